@@ -0,0 +1,227 @@
+package gonfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	consulAddrEnv     = "CONSUL_HTTP_ADDR"
+	consulTokenEnv    = "CONSUL_HTTP_TOKEN"
+	consulDefaultAddr = "http://127.0.0.1:8500"
+)
+
+// consulWait is the maximum duration of blocking queries used for watching keys
+var consulWait = 5 * time.Minute
+
+// ConsulProvider loads values from Consul KV store to provided struct
+// Keys under Prefix are mapped onto field paths, e.g. app/redis/hosts for Redis.Hosts
+type ConsulProvider struct {
+	// Address of Consul HTTP API, defaults to $CONSUL_HTTP_ADDR or "http://127.0.0.1:8500"
+	Address string
+
+	// Prefix of keys, e.g. "app" for keys like app/redis/hosts
+	Prefix string
+
+	// Token is the ACL token sent with requests, defaults to $CONSUL_HTTP_TOKEN
+	Token string
+
+	// Datacenter to query, defaults to datacenter of the agent
+	Datacenter string
+
+	// Whether to report error if no key is found under Prefix, defaults to false
+	Required bool
+
+	// Whether to report keys which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Client is used for sending requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+var (
+	_ Provider = (*ConsulProvider)(nil)
+	_ Filler   = (*ConsulProvider)(nil)
+	_ Watcher  = (*ConsulProvider)(nil)
+)
+
+// consulPair is a key value pair returned by KV endpoint, values are base64 encoded
+type consulPair struct {
+	Key   string
+	Value *string
+}
+
+// NewConsulProvider creates a new ConsulProvider for keys under prefix
+func NewConsulProvider(prefix string) *ConsulProvider {
+	return &ConsulProvider{
+		Address:    "",
+		Prefix:     prefix,
+		Token:      "",
+		Datacenter: "",
+		Required:   false,
+		Strict:     false,
+	}
+}
+
+// Name of provider
+func (cp *ConsulProvider) Name() string {
+	return "Consul provider"
+}
+
+// Fill takes struct fields and fills their values from keys under Prefix
+func (cp *ConsulProvider) Fill(in *Input) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	pairs, _, err := cp.get(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(pairs) == 0 {
+		if cp.Required {
+			return fmt.Errorf(remoteKeyNotFoundErrFormat, ErrKeyNotFound, cp.Prefix)
+		}
+
+		return nil
+	}
+
+	return fillKV(in, pairs, cp.source(), "/", cp.Name(), cp.Strict)
+}
+
+// Watch sends on changed whenever a key under Prefix is modified, using blocking queries
+// Failed requests are retried every pollInterval until ctx is done
+func (cp *ConsulProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	var index uint64
+	for {
+		_, current, err := cp.get(ctx, index)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(pollInterval):
+			}
+
+			continue
+		}
+
+		if index != 0 && current != index {
+			notify(changed)
+		}
+
+		// Index is reset if it goes backwards, e.g. after a snapshot restore
+		if current < index {
+			current = 0
+		}
+		index = current
+	}
+}
+
+// get returns values of keys under Prefix relative to it, along with the index of KV store
+// If index is not zero, the request blocks until it changes or consulWait elapses
+func (cp *ConsulProvider) get(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if cp.Datacenter != "" {
+		query.Set("dc", cp.Datacenter)
+	}
+	if index != 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%vs", consulWait.Seconds()))
+	}
+
+	u := cp.address() + "/v1/kv/" + strings.TrimLeft(cp.keyPrefix(), "/") + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if token := cp.token(); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	body, header, err := fetch(cp.Client, req)
+	current, _ := strconv.ParseUint(header.Get("X-Consul-Index"), 10, 64)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, current, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var list []consulPair
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, 0, fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	pairs := make(map[string]string, len(list))
+	for _, p := range list {
+		// Folders have no value
+		if p.Value == nil {
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(*p.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf(decodeFailedErrFormat, err)
+		}
+
+		pairs[strings.TrimPrefix(p.Key, cp.keyPrefix())] = string(value)
+	}
+
+	return pairs, current, nil
+}
+
+// address returns Address, falling back to $CONSUL_HTTP_ADDR and the default address
+// http scheme is used if not specified
+func (cp *ConsulProvider) address() string {
+	addr := cp.Address
+	if addr == "" {
+		addr = os.Getenv(consulAddrEnv)
+	}
+	if addr == "" {
+		addr = consulDefaultAddr
+	}
+
+	addr = strings.TrimRight(addr, "/")
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	return addr
+}
+
+// token returns Token, falling back to $CONSUL_HTTP_TOKEN
+func (cp *ConsulProvider) token() string {
+	if cp.Token != "" {
+		return cp.Token
+	}
+
+	return os.Getenv(consulTokenEnv)
+}
+
+// keyPrefix returns Prefix ending with "/", so keys of sibling prefixes like "application" are not matched for "app"
+func (cp *ConsulProvider) keyPrefix() string {
+	prefix := strings.Trim(cp.Prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+// source returns where values are found, followed by their keys
+func (cp *ConsulProvider) source() string {
+	return "consul:" + cp.keyPrefix()
+}
@@ -0,0 +1,260 @@
+package gonfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul emulates KV endpoints of Consul HTTP API, including blocking queries
+type fakeConsul struct {
+	mu      sync.Mutex
+	kv      map[string]string
+	index   uint64
+	updated chan struct{}
+	token   string
+	dc      string
+}
+
+func newFakeConsul(kv map[string]string) *fakeConsul {
+	return &fakeConsul{
+		kv:      kv,
+		index:   1,
+		updated: make(chan struct{}),
+	}
+}
+
+func (fc *fakeConsul) set(key, value string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.kv[key] = value
+	fc.index++
+	close(fc.updated)
+	fc.updated = make(chan struct{})
+}
+
+func (fc *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != fc.token {
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+	if dc := r.URL.Query().Get("dc"); dc != fc.dc {
+		http.Error(w, "No path to datacenter", http.StatusInternalServerError)
+		return
+	}
+
+	fc.mu.Lock()
+	index, updated := fc.index, fc.updated
+	fc.mu.Unlock()
+
+	if wait := r.URL.Query().Get("index"); wait == strconv.FormatUint(index, 10) {
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	var pairs []map[string]interface{}
+	for key, value := range fc.kv {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		pair := map[string]interface{}{"Key": key, "Value": nil}
+		if !strings.HasSuffix(key, "/") {
+			pair["Value"] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i]["Key"].(string) < pairs[j]["Key"].(string) })
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(fc.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+type consulConfig struct {
+	Host  string
+	Redis struct {
+		Hosts []string
+		Port  int
+	}
+	Servers []struct {
+		Host string
+	}
+}
+
+func TestNewConsulProvider(t *testing.T) {
+	cp := NewConsulProvider("app")
+	require.NotNil(t, cp)
+	assert.Equal(t, "", cp.Address)
+	assert.Equal(t, "app", cp.Prefix)
+	assert.Equal(t, "", cp.Token)
+	assert.Equal(t, "", cp.Datacenter)
+	assert.Equal(t, false, cp.Required)
+	assert.Equal(t, false, cp.Strict)
+	assert.Nil(t, cp.Client)
+}
+
+func TestConsulProvider_Name(t *testing.T) {
+	cp := NewConsulProvider("app")
+	assert.Equal(t, "Consul provider", cp.Name())
+}
+
+func TestConsulProvider_Fill(t *testing.T) {
+	fc := newFakeConsul(map[string]string{
+		"app/":               "",
+		"app/host":           "golang.org",
+		"app/redis/hosts":    "a.com b.com",
+		"app/redis/port":     "6379",
+		"app/servers/0/host": "s0.com",
+		"app/servers/1/host": "s1.com",
+		"application/host":   "other.org",
+	})
+	fc.token = "secret"
+	fc.dc = "eu"
+	server := httptest.NewServer(fc)
+	defer server.Close()
+
+	newProvider := func(prefix string) *ConsulProvider {
+		cp := NewConsulProvider(prefix)
+		cp.Address = server.URL
+		cp.Token = "secret"
+		cp.Datacenter = "eu"
+		return cp
+	}
+
+	t.Run("values", func(t *testing.T) {
+		var s consulConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		cp := newProvider("app")
+		cp.Strict = true
+		err = cp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "golang.org", s.Host)
+		assert.Equal(t, []string{"a.com", "b.com"}, s.Redis.Hosts)
+		assert.Equal(t, 6379, s.Redis.Port)
+		require.Len(t, s.Servers, 2)
+		assert.Equal(t, "s1.com", s.Servers[1].Host)
+
+		assert.Equal(t, "Consul provider", in.Fields[0].Provider)
+		assert.Equal(t, "consul:app/Host", in.Fields[0].Key)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+		err := os.Setenv(consulAddrEnv, strings.TrimPrefix(server.URL, "http://"))
+		require.NoError(t, err)
+		err = os.Setenv(consulTokenEnv, "secret")
+		require.NoError(t, err)
+
+		var s consulConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		cp := NewConsulProvider("/app/")
+		cp.Datacenter = "eu"
+		err = cp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "golang.org", s.Host)
+	})
+
+	t.Run("bad token", func(t *testing.T) {
+		in, err := NewInput(&consulConfig{})
+		require.NoError(t, err)
+
+		cp := newProvider("app")
+		cp.Token = "wrong"
+		err = cp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequestFailed))
+		assert.Contains(t, err.Error(), "403")
+		assert.NotContains(t, err.Error(), "wrong")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		in, err := NewInput(&consulConfig{})
+		require.NoError(t, err)
+
+		cp := newProvider("missing")
+		err = cp.Fill(in)
+		require.NoError(t, err)
+
+		cp.Required = true
+		err = cp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		fc.set("app/hots", "typo")
+
+		in, err := NewInput(&consulConfig{})
+		require.NoError(t, err)
+
+		cp := newProvider("app")
+		cp.Strict = true
+		err = cp.Fill(in)
+		require.Error(t, err)
+		ce, ok := err.(ConfigErrors)
+		require.True(t, ok)
+		require.Len(t, ce, 1)
+		assert.True(t, errors.Is(ce[0], ErrUnknownKey))
+		assert.Contains(t, ce[0].Error(), `did you mean "Host"`)
+	})
+}
+
+func TestConsulProvider_Watch(t *testing.T) {
+	fc := newFakeConsul(map[string]string{"app/host": "a.com"})
+	server := httptest.NewServer(fc)
+	defer server.Close()
+
+	cp := NewConsulProvider("app")
+	cp.Address = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error)
+	go func() {
+		done <- cp.Watch(ctx, changed)
+	}()
+
+	// Wait for the blocking query to be sent
+	time.Sleep(100 * time.Millisecond)
+	fc.set("app/host", "b.com")
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change was not detected")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,151 @@
+package gonfig
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// credentialsDirectoryEnv is set by systemd to the directory of credentials passed to a service
+const credentialsDirectoryEnv = "CREDENTIALS_DIRECTORY"
+
+// DirectoryProvider loads values from a directory containing a file per key to provided struct
+// e.g. mounted Kubernetes ConfigMaps and Secrets, Docker secrets and systemd credentials
+type DirectoryProvider struct {
+	// Dir is the path to directory, defaults to $CREDENTIALS_DIRECTORY set by systemd
+	Dir string
+
+	// Prefix is used when finding file names, defaults to ""
+	Prefix string
+
+	// SnakeCase specifies whether to convert field names to snake_case or not, defaults to true
+	// File names are matched case-insensitively
+	SnakeCase bool
+
+	// FieldSeparator is used to separate field names, defaults to "_"
+	FieldSeparator string
+
+	// Whether to report error if directory is not found, defaults to false
+	Required bool
+}
+
+var (
+	_ Provider = (*DirectoryProvider)(nil)
+	_ Filler   = (*DirectoryProvider)(nil)
+	_ Watcher  = (*DirectoryProvider)(nil)
+)
+
+// NewDirectoryProvider creates a new DirectoryProvider from specified directory
+func NewDirectoryProvider(dir string) *DirectoryProvider {
+	return &DirectoryProvider{
+		Dir:            dir,
+		Prefix:         "",
+		SnakeCase:      true,
+		FieldSeparator: "_",
+		Required:       false,
+	}
+}
+
+// Name of provider
+func (dp *DirectoryProvider) Name() string {
+	return "Directory provider"
+}
+
+// Fill takes struct fields and fills their values from content of files
+// File names are built from field paths the same way as env var names
+func (dp *DirectoryProvider) Fill(in *Input) error {
+	files, names, err := dp.readFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	source := func(key string) string {
+		if name, exists := names[key]; exists {
+			key = name
+		}
+
+		return filepath.Join(dp.dir(), key)
+	}
+
+	return dp.keys().fill(in, in.Fields, files, dp.Name(), source)
+}
+
+// Watch sends on changed whenever a file inside the directory is modified
+// It returns immediately if no directory is specified
+func (dp *DirectoryProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	dir := dp.dir()
+	if dir == "" {
+		return nil
+	}
+
+	return watchDirectory(ctx, dir, changed)
+}
+
+// keys returns an EnvProvider with the same settings, used for building UPPERCASE file names
+func (dp *DirectoryProvider) keys() *EnvProvider {
+	return &EnvProvider{
+		Prefix:         dp.Prefix,
+		SnakeCase:      dp.SnakeCase,
+		UpperCase:      true,
+		FieldSeparator: dp.FieldSeparator,
+		foldCase:       true,
+	}
+}
+
+// dir returns Dir, falling back to $CREDENTIALS_DIRECTORY
+func (dp *DirectoryProvider) dir() string {
+	if dp.Dir != "" {
+		return dp.Dir
+	}
+
+	return os.Getenv(credentialsDirectoryEnv)
+}
+
+// readFiles returns content of files inside directory along with their names, both mapped by UPPERCASE names
+// Trailing newlines are trimmed and hidden entries are skipped, e.g. "..data" of Kubernetes volumes
+func (dp *DirectoryProvider) readFiles() (files map[string]string, names map[string]string, err error) {
+	dir := dp.dir()
+	if dir == "" {
+		return nil, nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) && !dp.Required {
+			return nil, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	files = make(map[string]string, len(entries))
+	names = make(map[string]string, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		// Symlinks are followed, so only regular files are read
+		path := filepath.Join(dir, e.Name())
+		fi, err := os.Stat(path)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := strings.ToUpper(e.Name())
+		files[key] = strings.TrimRight(string(b), "\r\n")
+		names[key] = e.Name()
+	}
+
+	return files, names, nil
+}
@@ -0,0 +1,172 @@
+package gonfig
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDirectoryProvider(t *testing.T) {
+	dp := NewDirectoryProvider("/etc/config")
+	require.NotNil(t, dp)
+	assert.Equal(t, "/etc/config", dp.Dir)
+	assert.Equal(t, "", dp.Prefix)
+	assert.Equal(t, true, dp.SnakeCase)
+	assert.Equal(t, "_", dp.FieldSeparator)
+	assert.Equal(t, false, dp.Required)
+}
+
+func TestDirectoryProvider_Name(t *testing.T) {
+	dp := NewDirectoryProvider("")
+	assert.Equal(t, "Directory provider", dp.Name())
+}
+
+func TestDirectoryProvider_Fill(t *testing.T) {
+	t.Run("directory existence", func(t *testing.T) {
+		in, err := NewInput(&struct{}{})
+		require.NoError(t, err)
+
+		dp := NewDirectoryProvider("NotExistingDir")
+		err = dp.Fill(in)
+		assert.NoError(t, err)
+
+		dp.Required = true
+		err = dp.Fill(in)
+		assert.Error(t, err)
+	})
+
+	t.Run("should be set", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"db_host":          "localhost\n",
+			"db_port":          "5432",
+			"servers_0_host":   "a.com\r\n",
+			"SERVERS_1_HOST":   "b.com",
+			"db_password.save": "ignored",
+			".db_user":         "hidden",
+			"api-key":          "key",
+		})
+
+		s := struct {
+			DB struct {
+				Host     string
+				Port     int
+				User     string
+				Password string
+			}
+			Servers []struct {
+				Host string
+			}
+			APIKey string `config:"api-key"`
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		dp := NewDirectoryProvider(dir)
+		err = dp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", s.DB.Host)
+		assert.Equal(t, 5432, s.DB.Port)
+		assert.Equal(t, "", s.DB.User)
+		assert.Equal(t, "", s.DB.Password)
+		require.Len(t, s.Servers, 2)
+		assert.Equal(t, "a.com", s.Servers[0].Host)
+		assert.Equal(t, "b.com", s.Servers[1].Host)
+		assert.Equal(t, "key", s.APIKey)
+		assert.Equal(t, filepath.Join(dir, "db_host"), in.Fields[0].Key)
+	})
+
+	t.Run("kubernetes volume", func(t *testing.T) {
+		dir := t.TempDir()
+		data := filepath.Join(dir, "..2021_01_01_00_00_00.000000000")
+		require.NoError(t, os.Mkdir(data, 0700))
+		writeFiles(t, data, map[string]string{"DB_PASSWORD": "secret\n"})
+		require.NoError(t, os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "DB_PASSWORD"), filepath.Join(dir, "DB_PASSWORD")))
+
+		s := struct {
+			DB struct {
+				Password string `secret:"true"`
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		dp := NewDirectoryProvider(dir)
+		err = dp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", s.DB.Password)
+		assert.Equal(t, secretMask, in.Fields[0].RawValue)
+	})
+
+	t.Run("credentials directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"token": "abc"})
+		os.Clearenv()
+		require.NoError(t, os.Setenv(credentialsDirectoryEnv, dir))
+
+		s := struct {
+			Token string
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		err = NewDirectoryProvider("").Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", s.Token)
+	})
+}
+
+func TestDirectoryProvider_Watch(t *testing.T) {
+	setDuration(t, &pollInterval, 10*time.Millisecond)
+	os.Clearenv()
+
+	dp := NewDirectoryProvider("")
+	err := dp.Watch(context.Background(), make(chan struct{}))
+	assert.NoError(t, err)
+
+	for name, watch := range map[string]func(ctx context.Context, dir string, changed chan<- struct{}) error{
+		"watchDirectory": watchDirectory,
+		"pollDirectory":  pollDirectory,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			changed := make(chan struct{}, 1)
+			done := make(chan error)
+			go func() {
+				done <- watch(ctx, dir, changed)
+			}()
+
+			time.Sleep(30 * time.Millisecond)
+			writeFiles(t, dir, map[string]string{"host": "golang.org"})
+
+			select {
+			case <-changed:
+			case <-time.After(time.Second):
+				t.Fatal("change was not detected")
+			}
+
+			cancel()
+			assert.NoError(t, <-done)
+		})
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		require.NoError(t, err)
+	}
+}
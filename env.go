@@ -1,8 +1,10 @@
 package gonfig
 
 import (
+	"context"
 	"errors"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -28,11 +30,19 @@ type EnvProvider struct {
 
 	// Whether to report error if env file is not found, defaults to false
 	Required bool
+
+	// Whether to report env vars starting with Prefix which are not consumed by any field, defaults to false
+	// It has no effect without a Prefix
+	Strict bool
+
+	// foldCase makes keys UPPERCASE including config tags, used to match keys case-insensitively
+	foldCase bool
 }
 
 var (
 	_ Provider = (*EnvProvider)(nil)
 	_ Filler   = (*EnvProvider)(nil)
+	_ Watcher  = (*EnvProvider)(nil)
 )
 
 // NewEnvProvider creates a new EnvProvider
@@ -44,6 +54,7 @@ func NewEnvProvider() *EnvProvider {
 		FieldSeparator: "_",
 		Source:         "",
 		Required:       false,
+		Strict:         false,
 	}
 }
 
@@ -62,8 +73,49 @@ func (ep *EnvProvider) Fill(in *Input) error {
 		return nil
 	}
 
-	for _, f := range in.Fields {
-		value, err := ep.provide(envs, f.Tags.Config, f.Path)
+	if err := ep.fill(in, in.Fields, envs, ep.Name(), ep.sourceKey); err != nil {
+		return err
+	}
+
+	if (ep.Strict || in.strict) && ep.Prefix != "" {
+		return ep.unknownKeys(in, envs)
+	}
+
+	return nil
+}
+
+// fill sets values of fields, items of slice of structs are found by their index e.g. SERVERS_0_HOST
+// Fields are marked as set by provider name, and source returns where the value of a key is found
+func (ep *EnvProvider) fill(
+	in *Input,
+	fields []*Field,
+	envs map[string]string,
+	provider string,
+	source func(key string) string,
+) error {
+	for _, f := range fields {
+		key := ep.fieldKey(f)
+
+		if in.isStructList(f.Value.Type()) {
+			n := ep.countItems(envs, key)
+			if n == 0 {
+				continue
+			}
+
+			if err := in.setItems(f, n); err != nil {
+				return err
+			}
+			for _, item := range f.items {
+				if err := ep.fill(in, item.Fields, envs, provider, source); err != nil {
+					return err
+				}
+			}
+
+			f.markSet(provider, source(key+ep.FieldSeparator+"*"), "")
+			continue
+		}
+
+		value, err := ep.provide(envs, key)
 		if err != nil {
 			if errors.Is(err, ErrKeyNotFound) {
 				continue
@@ -77,12 +129,81 @@ func (ep *EnvProvider) Fill(in *Input) error {
 			return err
 		}
 
-		f.IsSet = true
+		f.markSet(provider, source(key), value)
 	}
 
 	return nil
 }
 
+// countItems returns number of items of a slice of structs based on the highest index found after key
+func (ep *EnvProvider) countItems(envs map[string]string, key string) int {
+	prefix := key + ep.FieldSeparator
+	n := 0
+	for k := range envs {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		index := strings.TrimPrefix(k, prefix)
+		if i := strings.Index(index, ep.FieldSeparator); i != -1 {
+			index = index[:i]
+		}
+
+		if i, err := strconv.Atoi(index); err == nil && i >= n {
+			n = i + 1
+		}
+	}
+
+	return n
+}
+
+// unknownKeys reports env vars starting with Prefix which are not consumed by any field
+func (ep *EnvProvider) unknownKeys(in *Input, envs map[string]string) error {
+	var known []string
+	consumed := make(map[string]bool)
+	for _, f := range in.allFields() {
+		if in.isStructList(f.Value.Type()) {
+			continue
+		}
+
+		key := ep.fieldKey(f)
+		known = append(known, key)
+		consumed[key] = true
+	}
+
+	var ce ConfigErrors
+	for _, key := range sortedKeys(envs) {
+		if strings.HasPrefix(key, ep.Prefix) && !consumed[key] {
+			ce = append(ce, unknownKeyError(key, known))
+		}
+	}
+
+	if len(ce) != 0 {
+		return ce
+	}
+
+	return nil
+}
+
+// overlay returns an optional EnvProvider for <name>.<profile>.env file next to Source file
+func (ep *EnvProvider) overlay(profile string) *EnvProvider {
+	overlay := *ep
+	overlay.Source = overlayPath(ep.Source, profile)
+	overlay.Required = false
+
+	return &overlay
+}
+
+// Watch sends on changed whenever the env file is modified
+// It returns immediately if no Source file is specified
+func (ep *EnvProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	if ep.Source == "" {
+		return nil
+	}
+
+	return watchFile(ctx, ep.Source, changed)
+}
+
 // envMap joins env vars from OS and optional env file and returns corresponding map
 func (ep *EnvProvider) envMap() (map[string]string, error) {
 	envs := envFromOS()
@@ -148,10 +269,9 @@ func envFromFile(path string) (map[string]string, error) {
 	return m, nil
 }
 
-// provide find a value from env variables based on specified key and path
-func (ep *EnvProvider) provide(content map[string]string, key string, path []string) (string, error) {
-	k := ep.buildKey(key, path)
-	value, exists := content[k]
+// provide find a value from env variables based on specified key
+func (ep *EnvProvider) provide(content map[string]string, key string) (string, error) {
+	value, exists := content[key]
 	if !exists {
 		return "", ErrKeyNotFound
 	}
@@ -159,10 +279,45 @@ func (ep *EnvProvider) provide(content map[string]string, key string, path []str
 	return value, nil
 }
 
-// buildKey prefix key with EnvPrefix, if not provided, path slice will be used
-func (ep *EnvProvider) buildKey(key string, path []string) string {
+// sourceKey prefixes key with path of env file if value is not provided by OS
+func (ep *EnvProvider) sourceKey(key string) string {
+	if _, exists := os.LookupEnv(key); exists || ep.Source == "" {
+		return key
+	}
+
+	return ep.Source + ":" + key
+}
+
+// fieldKey returns env var name of field
+// Prefix tags of enclosing structs replace their names and config tag is relative to the innermost one
+// Keys of slice of structs items are relative to the item e.g. SERVERS_0_HOST
+func (ep *EnvProvider) fieldKey(f *Field) string {
+	base, start := ep.Prefix, 0
+	if f.parent != nil {
+		start = len(f.parent.Path) + 1
+		base = ep.fieldKey(f.parent) + ep.FieldSeparator + f.Path[start-1] + ep.FieldSeparator
+	}
+
+	for _, p := range f.prefixes {
+		if names := f.Path[start : p.depth-1]; len(names) != 0 {
+			base += ep.joinKey("", names) + ep.FieldSeparator
+		}
+
+		base, start = base+p.value, p.depth
+	}
+
+	key := base + ep.joinKey(f.Tags.Config, f.Path[start:])
+	if ep.foldCase {
+		return strings.ToUpper(key)
+	}
+
+	return key
+}
+
+// joinKey returns key if provided, otherwise joins path slice by FieldSeparator
+func (ep *EnvProvider) joinKey(key string, path []string) string {
 	if key != "" {
-		return ep.Prefix + key
+		return key
 	}
 
 	k := strings.Join(path, ep.FieldSeparator)
@@ -173,7 +328,5 @@ func (ep *EnvProvider) buildKey(key string, path []string) string {
 		k = strings.ToUpper(k)
 	}
 
-	k = ep.Prefix + k
-
 	return k
 }
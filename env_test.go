@@ -1,6 +1,7 @@
 package gonfig
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -18,6 +19,7 @@ func TestNewEnvProvider(t *testing.T) {
 	assert.Equal(t, "_", ep.FieldSeparator)
 	assert.Equal(t, "", ep.Source)
 	assert.Equal(t, false, ep.Required)
+	assert.Equal(t, false, ep.Strict)
 }
 
 func TestEnvProvider_Name(t *testing.T) {
@@ -203,4 +205,134 @@ func TestEnvProvider_Fill(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "env", s.Nested.EnvVar)
 	})
+	t.Run("strict", func(t *testing.T) {
+		os.Clearenv()
+		for k, v := range map[string]string{
+			"APP_HOST":       "golang.org",
+			"APP_PORT":       "80",
+			"APP_REDIS_HOTS": "localhost",
+			"APP_UNKNOWN":    "value",
+			"OTHER":          "value",
+		} {
+			err := os.Setenv(k, v)
+			require.NoError(t, err)
+		}
+
+		s := struct {
+			Host  string
+			Port  int
+			Redis struct {
+				Hosts []string
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		ep := NewEnvProvider()
+		ep.Prefix = "APP_"
+
+		err = ep.Fill(in)
+		require.NoError(t, err)
+
+		ep.Strict = true
+		err = ep.Fill(in)
+		require.Error(t, err)
+		ce, ok := err.(ConfigErrors)
+		require.True(t, ok)
+		require.Len(t, ce, 2)
+		assert.EqualError(t, ce[0], `unknown key "APP_REDIS_HOTS", did you mean "APP_REDIS_HOSTS"?`)
+		assert.EqualError(t, ce[1], `unknown key "APP_UNKNOWN"`)
+		assert.True(t, errors.Is(err, ErrUnknownKey))
+		assert.Equal(t, "golang.org", s.Host)
+
+		ep.Prefix = ""
+		err = ep.Fill(in)
+		assert.NoError(t, err)
+	})
+	t.Run("slice of structs", func(t *testing.T) {
+		os.Clearenv()
+		for k, v := range map[string]string{
+			"APP_SERVERS_0_HOST": "a.com",
+			"APP_SERVERS_0_PORT": "80",
+			"APP_SERVERS_1_HOST": "b.com",
+			"APP_SERVERS_1_TAGS": "x y",
+			"APP_SERVERS_1_HOTS": "c.com",
+		} {
+			err := os.Setenv(k, v)
+			require.NoError(t, err)
+		}
+
+		type server struct {
+			Host string
+			Port int
+			Tags []string
+		}
+		s := struct {
+			Servers  []server
+			Pointers []*server `config:"SERVERS"`
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		ep := NewEnvProvider()
+		ep.Prefix = "APP_"
+		ep.Strict = true
+
+		err = ep.Fill(in)
+		require.Error(t, err)
+		assert.EqualError(t, err.(ConfigErrors)[0], `unknown key "APP_SERVERS_1_HOTS", did you mean "APP_SERVERS_1_HOST"?`)
+		assert.Equal(t, []server{{"a.com", 80, nil}, {"b.com", 0, []string{"x", "y"}}}, s.Servers)
+		require.Len(t, s.Pointers, 2)
+		assert.Equal(t, server{"a.com", 80, nil}, *s.Pointers[0])
+
+		fields := in.fieldsByPath()
+		require.Contains(t, fields, "Servers.1.Tags")
+		assert.Equal(t, "APP_SERVERS_1_TAGS", fields["Servers.1.Tags"].Key)
+		assert.False(t, fields["Servers.1.Port"].IsSet)
+	})
+	t.Run("struct prefix", func(t *testing.T) {
+		os.Clearenv()
+		for k, v := range map[string]string{
+			"APP_CACHE_HOST":        "cache",
+			"APP_CACHE_PORT_NUMBER": "1",
+			"APP_SESSION_HOST":      "session",
+			"APP_Q_MID_R_HOST":      "queue",
+			"APP_PLAIN_HOST":        "plain",
+			"APP_PORT_NUMBER":       "2",
+		} {
+			err := os.Setenv(k, v)
+			require.NoError(t, err)
+		}
+
+		type redisConfig struct {
+			Host string
+			Port int `config:"PORT_NUMBER"`
+		}
+		s := struct {
+			Cache   redisConfig  `prefix:"CACHE_"`
+			Session *redisConfig `prefix:"SESSION_"`
+			Queue   struct {
+				Mid struct {
+					Backend redisConfig `prefix:"R_"`
+				}
+			} `prefix:"Q_"`
+			Plain redisConfig
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		ep := NewEnvProvider()
+		ep.Prefix = "APP_"
+		ep.Strict = true
+
+		err = ep.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, redisConfig{"cache", 1}, s.Cache)
+		assert.Equal(t, redisConfig{"session", 0}, *s.Session)
+		assert.Equal(t, redisConfig{"queue", 0}, s.Queue.Mid.Backend)
+		assert.Equal(t, redisConfig{"plain", 2}, s.Plain)
+	})
 }
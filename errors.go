@@ -2,6 +2,8 @@ package gonfig
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"reflect"
 	"strings"
 )
@@ -20,6 +22,9 @@ var (
 	// ErrKeyNotFound is returned when no value found with specified key
 	ErrKeyNotFound = errors.New("key not found")
 
+	// ErrUnknownKey indicates that a key is not consumed by any field in strict mode
+	ErrUnknownKey = errors.New("unknown key")
+
 	// ErrRequiredField indicates that Field is required but no value is provided
 	ErrRequiredField = errors.New("field is required")
 
@@ -28,6 +33,28 @@ var (
 
 	// ErrValueOverflow indicates value overflow
 	ErrValueOverflow = errors.New("value overflow")
+
+	// ErrValidation indicates that value does not satisfy validation tags
+	ErrValidation = errors.New("validation failed")
+
+	// ErrHelp is returned by FlagProvider if -help or -h flag is passed but no such flag is defined
+	// Use Config.Usage to print usage text in this case
+	ErrHelp = flag.ErrHelp
+
+	// ErrUnsupportedMerge indicates that merge tag is not a strategy supported by type of field
+	ErrUnsupportedMerge = errors.New("unsupported merge strategy")
+
+	// ErrRequestFailed indicates that a remote provider received an unsuccessful response
+	ErrRequestFailed = errors.New("request failed")
+
+	// ErrFlagRedefined indicates that multiple fields are using the same flag name
+	ErrFlagRedefined = errors.New("flag redefined")
+
+	// ErrNoCredentials indicates that a remote provider found no credentials for signing requests
+	ErrNoCredentials = errors.New("no credentials found")
+
+	// ErrNoRegion indicates that a remote provider found no region to send requests to
+	ErrNoRegion = errors.New("no region found")
 )
 
 const (
@@ -39,6 +66,22 @@ const (
 	requiredFieldErrFormat      = `%w: no value found for "%v"`
 	parseErrFormat              = `%w at "%v": %v`
 	overflowErrFormat           = `%w: "%v" overflows type "%v" at "%v"`
+	flagRedefinedErrFormat      = `%w: "%v" at "%v"`
+	validationErrFormat         = `%w at "%v": %v`
+	requiredWhenErrFormat       = `%w: no value found for "%v" when %v`
+	validatorErrFormat          = `%v: %w`
+	unknownKeyErrFormat         = `%w "%v"`
+	unknownKeySuggestErrFormat  = `%w "%v", did you mean "%v"?`
+	fileNotFoundErrFormat       = `%w: no file named "%v" found in %v`
+	noMatchErrFormat            = `%w: no file matches "%v"`
+	sourceErrFormat             = `%v: %w`
+	unsupportedMergeErrFormat   = `%w "%v" for type "%v"`
+	requestErrFormat            = `%w: %v %v: %v`
+	remoteKeyNotFoundErrFormat  = `%w: no key found under "%v"`
+	badVaultRefErrFormat        = `bad vault reference "%v" at "%v": expected "<path>#<key>"`
+	nonStructTypeErrFormat      = `gonfig: type argument of New must be a struct, got %v`
+	noAWSCredentialsErrFormat   = `%w for AWS: set %v and %v, or run with an ECS task role`
+	noAWSRegionErrFormat        = `%w for AWS: set %v or %v`
 )
 
 // An InvalidInputError describes an invalid argument passed to Into function
@@ -83,3 +126,38 @@ func (ce ConfigErrors) Error() string {
 
 	return strings.TrimSpace(msg)
 }
+
+// Is reports whether any of the collected errors matches target
+func (ce ConfigErrors) Is(target error) bool {
+	for i := range ce {
+		if errors.Is(ce[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unknownKeyError reports an unknown key along with the closest known key as suggestion
+func unknownKeyError(key string, known []string) error {
+	if suggestion := closestKey(key, known); suggestion != "" {
+		return fmt.Errorf(unknownKeySuggestErrFormat, ErrUnknownKey, key, suggestion)
+	}
+
+	return fmt.Errorf(unknownKeyErrFormat, ErrUnknownKey, key)
+}
+
+// prefixErrors prefixes err, or each of the collected errors, with source of the error e.g. a file path
+func prefixErrors(source string, err error) error {
+	ce, ok := err.(ConfigErrors)
+	if !ok {
+		return fmt.Errorf(sourceErrFormat, source, err)
+	}
+
+	prefixed := make(ConfigErrors, len(ce))
+	for i := range ce {
+		prefixed[i] = fmt.Errorf(sourceErrFormat, source, ce[i])
+	}
+
+	return prefixed
+}
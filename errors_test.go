@@ -2,6 +2,7 @@ package gonfig
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -58,3 +59,13 @@ func TestConfigErrors_Error(t *testing.T) {
 
 	assert.EqualError(t, ce, "gonfig:\n  * first\n  * second\n  * third")
 }
+
+func TestConfigErrors_Is(t *testing.T) {
+	ce := ConfigErrors{
+		errors.New("first"),
+		fmt.Errorf("second: %w", ErrRequiredField),
+	}
+
+	assert.True(t, errors.Is(ce, ErrRequiredField))
+	assert.False(t, errors.Is(ce, ErrParsing))
+}
@@ -0,0 +1,365 @@
+package gonfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	etcdEndpointEnv     = "ETCD_ENDPOINT"
+	etcdDefaultEndpoint = "http://127.0.0.1:2379"
+)
+
+// EtcdProvider loads values from etcd v3 to provided struct, using the JSON gateway of etcd
+// Keys under Prefix are mapped onto field paths, e.g. /app/db/host for DB.Host
+type EtcdProvider struct {
+	// Endpoint of etcd JSON gateway, defaults to $ETCD_ENDPOINT or "http://127.0.0.1:2379"
+	Endpoint string
+
+	// Prefix of keys including the trailing separator, e.g. "/app/" for keys like /app/db/host
+	Prefix string
+
+	// Username and Password are used for authentication if specified
+	Username string
+	Password string
+
+	// Whether to report error if no key is found under Prefix, defaults to false
+	Required bool
+
+	// Whether to report keys which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Client is used for sending requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	// Revision of keys read by the last call to Fill, Watch starts after it so no change is missed
+	mu       sync.Mutex
+	revision int64
+}
+
+var (
+	_ Provider = (*EtcdProvider)(nil)
+	_ Filler   = (*EtcdProvider)(nil)
+	_ Watcher  = (*EtcdProvider)(nil)
+)
+
+// etcdKV is a key value pair of etcd, keys and values are base64 encoded
+// ModRevision is the revision of the last change of the key, encoded as string by the gateway
+type etcdKV struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value"`
+	ModRevision json.Number `json:"mod_revision,omitempty"`
+}
+
+// etcdHeader is the response header of etcd, int64 values are encoded as strings by the gateway
+type etcdHeader struct {
+	Revision json.Number `json:"revision"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader `json:"header"`
+	Kvs    []etcdKV   `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Canceled        bool        `json:"canceled"`
+		CompactRevision json.Number `json:"compact_revision"`
+		Events          []struct {
+			Kv etcdKV `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewEtcdProvider creates a new EtcdProvider for keys under prefix
+func NewEtcdProvider(prefix string) *EtcdProvider {
+	return &EtcdProvider{
+		Endpoint: "",
+		Prefix:   prefix,
+		Required: false,
+		Strict:   false,
+	}
+}
+
+// Name of provider
+func (ep *EtcdProvider) Name() string {
+	return "Etcd provider"
+}
+
+// Fill takes struct fields and fills their values from keys under Prefix
+func (ep *EtcdProvider) Fill(in *Input) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	token, err := ep.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	var resp etcdRangeResponse
+	if err := ep.post(ctx, "/v3/kv/range", token, ep.keyRange(), &resp); err != nil {
+		return err
+	}
+
+	if rev, err := resp.Header.Revision.Int64(); err == nil {
+		ep.mu.Lock()
+		ep.revision = rev
+		ep.mu.Unlock()
+	}
+
+	pairs := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		key, value, err := kv.decode()
+		if err != nil {
+			return err
+		}
+
+		pairs[strings.TrimPrefix(key, ep.Prefix)] = value
+	}
+
+	if len(pairs) == 0 {
+		if ep.Required {
+			return fmt.Errorf(remoteKeyNotFoundErrFormat, ErrKeyNotFound, ep.Prefix)
+		}
+
+		return nil
+	}
+
+	return fillKV(in, pairs, "etcd:"+ep.Prefix, "/", ep.Name(), ep.Strict)
+}
+
+// Watch streams watch events of keys under Prefix and sends on changed for each of them
+// Events are watched after the revision read by the last call to Fill, or from now if Fill is not called
+// Broken streams are resumed from the last revision seen, retrying every pollInterval until ctx is done
+func (ep *EtcdProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	ep.mu.Lock()
+	revision := ep.revision
+	ep.mu.Unlock()
+
+	for {
+		rev, err := ep.watch(ctx, revision, changed)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Revisions are compacted, so changes may have been missed
+		// Watching is resumed from the oldest revision available
+		if err == errEtcdCompacted {
+			revision = rev
+			notify(changed)
+			continue
+		}
+
+		if rev > revision {
+			revision = rev
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// errEtcdCompacted is returned when watch is canceled because the requested revision is compacted
+var errEtcdCompacted = errors.New("etcd: revision compacted")
+
+// watch streams events after revision until the stream breaks, returning revision of the last event delivered
+// In case of compaction, it returns the revision before the oldest one available along with errEtcdCompacted
+func (ep *EtcdProvider) watch(ctx context.Context, revision int64, changed chan<- struct{}) (int64, error) {
+	token, err := ep.authenticate(ctx)
+	if err != nil {
+		return revision, err
+	}
+
+	create := ep.keyRange()
+	if revision != 0 {
+		create["start_revision"] = strconv.FormatInt(revision+1, 10)
+	}
+
+	req, err := ep.request(ctx, "/v3/watch", token, map[string]interface{}{"create_request": create})
+	if err != nil {
+		return revision, err
+	}
+
+	client := ep.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return revision, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return revision, fmt.Errorf(requestErrFormat, ErrRequestFailed, req.Method, req.URL.Redacted(), resp.Status)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var wr etcdWatchResponse
+		if err := dec.Decode(&wr); err != nil {
+			return revision, err
+		}
+		if wr.Error != nil {
+			return revision, fmt.Errorf(requestErrFormat, ErrRequestFailed, req.Method, req.URL.Redacted(), wr.Error.Message)
+		}
+
+		// Header holds the current revision rather than the one of delivered events,
+		// so it is not used to avoid skipping events not delivered yet
+		for _, e := range wr.Result.Events {
+			if rev, err := e.Kv.ModRevision.Int64(); err == nil && rev > revision {
+				revision = rev
+			}
+		}
+
+		if wr.Result.Canceled {
+			if compact, err := wr.Result.CompactRevision.Int64(); err == nil && compact > 0 {
+				return compact - 1, errEtcdCompacted
+			}
+
+			return revision, nil
+		}
+
+		if len(wr.Result.Events) != 0 {
+			notify(changed)
+		}
+	}
+}
+
+// authenticate returns a token for Username and Password, or an empty token if not specified
+func (ep *EtcdProvider) authenticate(ctx context.Context) (string, error) {
+	if ep.Username == "" {
+		return "", nil
+	}
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	body := map[string]interface{}{"name": ep.Username, "password": ep.Password}
+	if err := ep.post(ctx, "/v3/auth/authenticate", "", body, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Token, nil
+}
+
+// post sends body as JSON to path of endpoint and decodes the response into v
+func (ep *EtcdProvider) post(ctx context.Context, path, token string, body, v interface{}) error {
+	req, err := ep.request(ctx, path, token, body)
+	if err != nil {
+		return err
+	}
+
+	b, _, err := fetch(ep.Client, req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	return nil
+}
+
+// request returns a POST request of body as JSON to path of endpoint, authorized by token if not empty
+func (ep *EtcdProvider) request(ctx context.Context, path, token string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.endpoint()+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	return req, nil
+}
+
+// keyRange returns the base64 encoded range of keys under Prefix
+func (ep *EtcdProvider) keyRange() map[string]interface{} {
+	key, end := []byte(ep.Prefix), prefixEnd([]byte(ep.Prefix))
+
+	// A zero byte key and range end request all keys
+	if len(key) == 0 {
+		key = []byte{0}
+	}
+
+	return map[string]interface{}{
+		"key":       base64.StdEncoding.EncodeToString(key),
+		"range_end": base64.StdEncoding.EncodeToString(end),
+	}
+}
+
+// endpoint returns Endpoint, falling back to $ETCD_ENDPOINT and the default endpoint
+// http scheme is used if not specified
+func (ep *EtcdProvider) endpoint() string {
+	endpoint := ep.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv(etcdEndpointEnv)
+	}
+	if endpoint == "" {
+		endpoint = etcdDefaultEndpoint
+	}
+
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	return endpoint
+}
+
+// decode returns decoded key and value
+func (kv etcdKV) decode() (string, string, error) {
+	key, err := base64.StdEncoding.DecodeString(kv.Key)
+	if err != nil {
+		return "", "", fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return "", "", fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	return string(key), string(value), nil
+}
+
+// prefixEnd returns the range end of keys with prefix, which is prefix with its last byte incremented
+// Trailing 0xff bytes are dropped, and a zero byte is returned if all bytes are 0xff or prefix is empty
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return []byte{0}
+}
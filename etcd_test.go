@@ -0,0 +1,432 @@
+package gonfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcd emulates range, watch and authenticate endpoints of etcd v3 JSON gateway
+// Revisions before compacted are not available for watching
+// Streams of the next drops watches are closed right after they are created
+type fakeEtcd struct {
+	mu        sync.Mutex
+	kv        map[string]string
+	revision  int64
+	compacted int64
+	drops     int
+	history   []fakeEtcdEvent
+	starts    []int64
+	watchers  []chan fakeEtcdEvent
+	password  string
+}
+
+type fakeEtcdEvent struct {
+	key      string
+	revision int64
+}
+
+func newFakeEtcd(kv map[string]string) *fakeEtcd {
+	return &fakeEtcd{kv: kv, revision: 1}
+}
+
+func (fe *fakeEtcd) put(key, value string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	fe.kv[key] = value
+	fe.revision++
+	e := fakeEtcdEvent{key: key, revision: fe.revision}
+	fe.history = append(fe.history, e)
+	for _, w := range fe.watchers {
+		w <- e
+	}
+}
+
+func (fe *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/v3/auth/authenticate" {
+		if body["name"] != "root" || body["password"] != fe.password {
+			http.Error(w, `{"error":"authentication failed"}`, http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "token-" + fe.password})
+		return
+	}
+
+	if fe.password != "" && r.Header.Get("Authorization") != "token-"+fe.password {
+		http.Error(w, `{"error":"invalid auth token"}`, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		fe.mu.Lock()
+		defer fe.mu.Unlock()
+
+		key, end := decodeRange(body)
+		var kvs []etcdKV
+		for k, v := range fe.kv {
+			if inRange(k, key, end) {
+				kvs = append(kvs, etcdKV{
+					Key:   base64.StdEncoding.EncodeToString([]byte(k)),
+					Value: base64.StdEncoding.EncodeToString([]byte(v)),
+				})
+			}
+		}
+		sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": map[string]string{"revision": strconv.FormatInt(fe.revision, 10)},
+			"kvs":    kvs,
+		})
+
+	case "/v3/watch":
+		create := body["create_request"].(map[string]interface{})
+		key, end := decodeRange(create)
+		start, _ := strconv.ParseInt(fmt.Sprint(create["start_revision"]), 10, 64)
+		events := make(chan fakeEtcdEvent, 10)
+		enc := json.NewEncoder(w)
+
+		fe.mu.Lock()
+		fe.starts = append(fe.starts, start)
+		if start != 0 && start < fe.compacted {
+			fe.mu.Unlock()
+			_ = enc.Encode(map[string]interface{}{"result": map[string]interface{}{
+				"canceled":         true,
+				"compact_revision": strconv.FormatInt(fe.compacted, 10),
+			}})
+			return
+		}
+
+		// Events since start revision are replayed
+		for _, e := range fe.history {
+			if start != 0 && e.revision >= start {
+				events <- e
+			}
+		}
+		header := map[string]string{"revision": strconv.FormatInt(fe.revision, 10)}
+		drop := fe.drops > 0
+		if drop {
+			fe.drops--
+		} else {
+			fe.watchers = append(fe.watchers, events)
+		}
+		fe.mu.Unlock()
+
+		_ = enc.Encode(map[string]interface{}{"result": map[string]interface{}{"header": header, "created": true}})
+		w.(http.Flusher).Flush()
+		if drop {
+			return
+		}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+
+			case e := <-events:
+				if !inRange(e.key, key, end) {
+					continue
+				}
+
+				fe.mu.Lock()
+				header := map[string]string{"revision": strconv.FormatInt(fe.revision, 10)}
+				fe.mu.Unlock()
+				kv := etcdKV{
+					Key:         base64.StdEncoding.EncodeToString([]byte(e.key)),
+					ModRevision: json.Number(strconv.FormatInt(e.revision, 10)),
+				}
+				_ = enc.Encode(map[string]interface{}{"result": map[string]interface{}{
+					"header": header,
+					"events": []interface{}{map[string]interface{}{"kv": kv}},
+				}})
+				w.(http.Flusher).Flush()
+			}
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func decodeRange(body map[string]interface{}) (string, string) {
+	key, _ := base64.StdEncoding.DecodeString(body["key"].(string))
+	end, _ := base64.StdEncoding.DecodeString(body["range_end"].(string))
+	return string(key), string(end)
+}
+
+func inRange(k, key, end string) bool {
+	return k >= key && (end == "\x00" || k < end)
+}
+
+type etcdConfig struct {
+	DB struct {
+		Host string
+		Port int
+	}
+	Servers []struct {
+		Host string
+	}
+}
+
+func TestNewEtcdProvider(t *testing.T) {
+	ep := NewEtcdProvider("/app/")
+	require.NotNil(t, ep)
+	assert.Equal(t, "", ep.Endpoint)
+	assert.Equal(t, "/app/", ep.Prefix)
+	assert.Equal(t, "", ep.Username)
+	assert.Equal(t, false, ep.Required)
+	assert.Equal(t, false, ep.Strict)
+}
+
+func TestEtcdProvider_Name(t *testing.T) {
+	ep := NewEtcdProvider("/app/")
+	assert.Equal(t, "Etcd provider", ep.Name())
+}
+
+func TestEtcdProvider_Fill(t *testing.T) {
+	fe := newFakeEtcd(map[string]string{
+		"/app/db/host":        "localhost",
+		"/app/db/port":        "5432",
+		"/app/servers/0/host": "s0.com",
+		"/app0/db/host":       "other",
+		"/apps/db/host":       "other",
+	})
+	server := httptest.NewServer(fe)
+	defer server.Close()
+
+	t.Run("values", func(t *testing.T) {
+		var s etcdConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		ep := NewEtcdProvider("/app/")
+		ep.Endpoint = server.URL
+		ep.Strict = true
+		err = ep.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", s.DB.Host)
+		assert.Equal(t, 5432, s.DB.Port)
+		require.Len(t, s.Servers, 1)
+		assert.Equal(t, "s0.com", s.Servers[0].Host)
+		assert.Equal(t, "etcd:/app/DB/Host", in.Fields[0].Key)
+	})
+
+	t.Run("authentication", func(t *testing.T) {
+		fe.password = "secret"
+		defer func() { fe.password = "" }()
+
+		var s etcdConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		ep := NewEtcdProvider("/app/")
+		ep.Endpoint = server.URL
+		err = ep.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequestFailed))
+
+		ep.Username = "root"
+		ep.Password = "wrong"
+		err = ep.Fill(in)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "wrong")
+
+		ep.Password = "secret"
+		err = ep.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", s.DB.Host)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		in, err := NewInput(&etcdConfig{})
+		require.NoError(t, err)
+
+		ep := NewEtcdProvider("/missing/")
+		ep.Endpoint = server.URL
+		err = ep.Fill(in)
+		require.NoError(t, err)
+
+		ep.Required = true
+		err = ep.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+}
+
+func TestEtcdProvider_Watch(t *testing.T) {
+	fe := newFakeEtcd(map[string]string{"/app/db/host": "a"})
+	server := httptest.NewServer(fe)
+	defer server.Close()
+
+	ep := NewEtcdProvider("/app/")
+	ep.Endpoint = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error)
+	go func() {
+		done <- ep.Watch(ctx, changed)
+	}()
+
+	// Wait for the watch to be created
+	time.Sleep(100 * time.Millisecond)
+	fe.put("/other/db/host", "b")
+	fe.put("/app/db/host", "b")
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change was not detected")
+	}
+
+	select {
+	case <-changed:
+		t.Fatal("change out of prefix was detected")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestEtcdProvider_Watch_revision(t *testing.T) {
+	setDuration(t, &pollInterval, 10*time.Millisecond)
+
+	watch := func(t *testing.T, ep *EtcdProvider) (chan struct{}, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		changed := make(chan struct{}, 1)
+		done := make(chan error)
+		go func() {
+			done <- ep.Watch(ctx, changed)
+		}()
+
+		return changed, func() {
+			cancel()
+			assert.NoError(t, <-done)
+		}
+	}
+
+	waitChange := func(t *testing.T, changed <-chan struct{}) {
+		t.Helper()
+
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("change was not detected")
+		}
+	}
+
+	t.Run("changes after fill", func(t *testing.T) {
+		fe := newFakeEtcd(map[string]string{"/app/db/host": "a"})
+		server := httptest.NewServer(fe)
+		defer server.Close()
+
+		ep := NewEtcdProvider("/app/")
+		ep.Endpoint = server.URL
+		var s etcdConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NoError(t, ep.Fill(in))
+
+		// Changed before watching started
+		fe.put("/app/db/host", "b")
+
+		changed, stop := watch(t, ep)
+		defer stop()
+		waitChange(t, changed)
+
+		fe.mu.Lock()
+		defer fe.mu.Unlock()
+		assert.Equal(t, []int64{2}, fe.starts)
+	})
+
+	t.Run("stream broken after created", func(t *testing.T) {
+		fe := newFakeEtcd(map[string]string{"/app/db/host": "a"})
+		server := httptest.NewServer(fe)
+		defer server.Close()
+
+		ep := NewEtcdProvider("/app/")
+		ep.Endpoint = server.URL
+		var s etcdConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NoError(t, ep.Fill(in))
+
+		fe.put("/app/db/host", "b")
+		fe.put("/app/db/port", "80")
+		fe.mu.Lock()
+		fe.drops = 1
+		fe.mu.Unlock()
+
+		changed, stop := watch(t, ep)
+		defer stop()
+		waitChange(t, changed)
+
+		// Changes replayed after the created response are not skipped by its header revision
+		fe.mu.Lock()
+		defer fe.mu.Unlock()
+		assert.Equal(t, []int64{2, 2}, fe.starts)
+	})
+
+	t.Run("compacted", func(t *testing.T) {
+		fe := newFakeEtcd(map[string]string{"/app/db/host": "a"})
+		server := httptest.NewServer(fe)
+		defer server.Close()
+
+		ep := NewEtcdProvider("/app/")
+		ep.Endpoint = server.URL
+		var s etcdConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NoError(t, ep.Fill(in))
+
+		fe.put("/app/db/host", "b")
+		fe.put("/app/db/port", "80")
+		fe.mu.Lock()
+		fe.compacted = 3
+		fe.mu.Unlock()
+
+		changed, stop := watch(t, ep)
+		defer stop()
+		waitChange(t, changed)
+
+		// Watching is resumed from the oldest available revision instead of now
+		require.Eventually(t, func() bool {
+			fe.mu.Lock()
+			defer fe.mu.Unlock()
+			return len(fe.starts) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		fe.mu.Lock()
+		defer fe.mu.Unlock()
+		assert.Equal(t, []int64{2, 3}, fe.starts)
+	})
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("/app0"), prefixEnd([]byte("/app/")))
+	assert.Equal(t, []byte{'a', 0x01}, prefixEnd([]byte{'a', 0x00}))
+	assert.Equal(t, []byte{'b'}, prefixEnd([]byte{'a', 0xff}))
+	assert.Equal(t, []byte{0}, prefixEnd([]byte{0xff}))
+	assert.Equal(t, []byte{0}, prefixEnd(nil))
+}
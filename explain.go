@@ -0,0 +1,39 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// defaultProviderName is reported as provider of fields populated from default tag
+const defaultProviderName = "default tag"
+
+// Explain returns a report of where the value of each field came from, during the last call to Into or reload by Watch
+// Fields without a provider are neither set by providers nor have a default value
+func (c *Config) Explain() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.in == nil {
+		return ""
+	}
+
+	var b bytes.Buffer
+	_, _ = fmt.Fprintf(&b, "Values of %v:\n", c.in.Name)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	writeTableRow(tw, []string{"FIELD", "VALUE", "PROVIDER", "KEY"})
+	for _, f := range c.in.allFields() {
+		writeTableRow(tw, []string{
+			strings.Join(f.Path, "."),
+			f.RawValue,
+			f.Provider,
+			f.Key,
+		})
+	}
+	_ = tw.Flush()
+
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type markingProvider struct{}
+
+func (mp *markingProvider) Name() string {
+	return "marking provider"
+}
+
+func (mp *markingProvider) Fill(in *Input) error {
+	for _, f := range in.Fields {
+		if f.Path[0] == "Custom" {
+			f.IsSet = true
+		}
+	}
+
+	return nil
+}
+
+func TestConfig_Explain(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("APP_ENV", "env")
+	require.NoError(t, err)
+
+	s := struct {
+		Env     string
+		File    string `json:"custom_key"`
+		Default int    `default:"5"`
+		Custom  string
+		Unset   string
+	}{}
+
+	ep := NewEnvProvider()
+	ep.Prefix = "APP_"
+	c := Load().AddProvider(ep).FromFile("testdata/config.json").AddProvider(new(markingProvider))
+	assert.Equal(t, "", c.Explain())
+
+	err = c.Into(&s)
+	require.NoError(t, err)
+
+	expected := "" +
+		"Values of *struct { Env string; File string \"json:\\\"custom_key\\\"\"; Default int \"default:\\\"5\\\"\"; Custom string; Unset string }:\n" +
+		"  FIELD    VALUE   PROVIDER              KEY\n" +
+		"  Env      env     ENV provider          APP_ENV\n" +
+		"  File     custom  File provider (json)  testdata/config.json:custom_key\n" +
+		"  Default  5       default tag           default\n" +
+		"  Custom           marking provider      \n" +
+		"  Unset                                  \n"
+	assert.Equal(t, expected, c.Explain())
+}
+
+func TestEnvProvider_Fill_source(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("OS", "env from os")
+	require.NoError(t, err)
+
+	s := struct {
+		Os   string
+		File string
+	}{}
+	in, err := NewInput(&s)
+	require.NoError(t, err)
+
+	ep := NewEnvProvider()
+	ep.Source = "testdata/.env"
+	err = ep.Fill(in)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ENV provider", in.Fields[0].Provider)
+	assert.Equal(t, "OS", in.Fields[0].Key)
+	assert.Equal(t, "env from os", in.Fields[0].RawValue)
+	assert.Equal(t, "testdata/.env:FILE", in.Fields[1].Key)
+	assert.Equal(t, "env from file", in.Fields[1].RawValue)
+}
@@ -1,12 +1,15 @@
 package gonfig
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
@@ -32,12 +35,25 @@ type FileProvider struct {
 
 	// Whether to report error if file is not found, defaults to false
 	Required bool
+
+	// Whether to report keys in file which are not consumed by any field, defaults to false
+	Strict bool
+
+	// keySeparator joins keys of values found, set by providers of key value stores e.g. "/"
+	keySeparator string
+
+	// secret specifies whether values are redacted in errors and provenance, set by providers of secret stores
+	secret bool
+
+	// origin returns the file which value at keys comes from, set by providers merging multiple files
+	origin func(keys [][]string) string
 }
 
 var (
 	_ Provider    = (*FileProvider)(nil)
 	_ Unmarshaler = (*FileProvider)(nil)
 	_ Filler      = (*FileProvider)(nil)
+	_ Watcher     = (*FileProvider)(nil)
 )
 
 // NewFileProvider creates a new FileProvider from specified path
@@ -46,6 +62,7 @@ func NewFileProvider(path string) *FileProvider {
 		FilePath: path,
 		FileExt:  filepath.Ext(path),
 		Required: false,
+		Strict:   false,
 	}
 }
 
@@ -54,56 +71,159 @@ func (fp *FileProvider) Name() string {
 	return fmt.Sprintf("File provider (%v)", fp.FileExt[1:])
 }
 
+// overlay returns an optional FileProvider for <name>.<profile>.<ext> file next to the file
+func (fp *FileProvider) overlay(profile string) *FileProvider {
+	overlay := *fp
+	overlay.FilePath = overlayPath(fp.FilePath, profile)
+	overlay.Required = false
+
+	return &overlay
+}
+
+// overlayPath returns path of <name>.<profile>.<ext> file next to the file
+func overlayPath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
 // UnmarshalStruct takes a struct pointer and loads values from provided file into it
+//
+// Deprecated: Values are set field by field the same as Fill, which is used by Config.Into instead.
 func (fp *FileProvider) UnmarshalStruct(i interface{}) error {
-	return fp.decode(i)
+	in, err := NewInput(i)
+	if err != nil {
+		return err
+	}
+
+	return fp.Fill(in)
 }
 
-// Fill takes struct fields and and checks if their value is set
+// Fill takes struct fields and fills their values from file content
+// Values go through the same conversion as other providers
 func (fp *FileProvider) Fill(in *Input) error {
-	var content map[string]interface{}
-	if err := fp.decode(&content); err != nil {
+	content, err := fp.decode()
+	if err != nil {
 		return err
 	}
 
-	for _, f := range in.Fields {
-		if f.IsSet {
+	if err := fp.fill(in, in.Fields, content, fp.Name()); err != nil {
+		return err
+	}
+
+	if fp.Strict || in.strict {
+		return fp.unknownKeys(in, content)
+	}
+
+	return nil
+}
+
+// fill sets values of fields found in content
+// Items of slice of structs are created from arrays of objects and filled field by field
+// Fields are marked as set by provider name
+func (fp *FileProvider) fill(in *Input, fields []*Field, content map[string]interface{}, provider string) error {
+	for _, f := range fields {
+		value, err := fp.provide(content, f)
+		if err != nil {
 			continue
 		}
 
-		var key string
-		switch fp.FileExt {
-		case JSON:
-			key = f.Tags.Json
-		case YML, YAML:
-			key = f.Tags.Yaml
-		case TOML:
-			key = f.Tags.Toml
+		// Values of secret stores are redacted in errors and provenance
+		if fp.secret {
+			f.secret = true
 		}
 
-		if _, err := fp.provide(content, key, f.Path); err == nil {
-			f.IsSet = true
+		var raw string
+		if list, ok := toList(value); ok && in.isStructList(f.Value.Type()) {
+			if err := in.setItems(f, len(list)); err != nil {
+				return err
+			}
+			for _, item := range f.items {
+				if err := fp.fill(in, item.Fields, content, provider); err != nil {
+					return err
+				}
+			}
+		} else {
+			if err := in.setContent(f, value); err != nil {
+				return err
+			}
+			raw = in.formatContent(f, value)
 		}
+
+		f.markSet(provider, fp.sourceKey(f), raw)
 	}
 
 	return nil
 }
 
-// decode opens specified file and loads its content to input argument
-func (fp *FileProvider) decode(i interface{}) (err error) {
+// unknownKeys reports keys in file content which are not consumed by any field
+// Keys are matched case-insensitively, whole content of a key consumed by a field is considered known
+func (fp *FileProvider) unknownKeys(in *Input, content map[string]interface{}) error {
+	var known, consumers []string
+	for _, f := range in.allFields() {
+		key := strings.Join(fp.fieldPath(f), ".")
+		known = append(known, key)
+
+		// Keys of items are consumed by fields of each item
+		if in.isStructList(f.Value.Type()) {
+			continue
+		}
+
+		consumers = append(consumers, key)
+
+		// Values may be found by alternative keys of a level
+		if _, path, exists := traverseMap(content, fp.fieldKeys(f)); exists {
+			consumers = append(consumers, strings.Join(path, "."))
+		}
+	}
+
+	var ce ConfigErrors
+	for _, key := range flattenMap(content, "") {
+		if !isConsumed(key, consumers) && !containsFold(known, key) {
+			ce = append(ce, unknownKeyError(key, known))
+		}
+	}
+
+	if len(ce) != 0 {
+		return ce
+	}
+
+	return nil
+}
+
+// isConsumed reports whether key or one of its parents is a known key
+func isConsumed(key string, known []string) bool {
+	key = strings.ToLower(key)
+	for _, k := range known {
+		k = strings.ToLower(k)
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Watch sends on changed whenever the file is modified
+func (fp *FileProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	return watchFile(ctx, fp.FilePath, changed)
+}
+
+// decode opens specified file and returns its content
+// A missing file results in empty content unless it is required
+func (fp *FileProvider) decode() (content map[string]interface{}, err error) {
 	switch fp.FileExt {
 	case JSON, YML, YAML, TOML:
 	default:
-		return fmt.Errorf(unsupportedFileExtErrFormat, ErrUnsupportedFileExt, fp.FileExt)
+		return nil, fmt.Errorf(unsupportedFileExtErrFormat, ErrUnsupportedFileExt, fp.FileExt)
 	}
 
 	f, err := os.Open(fp.FilePath)
 	if err != nil {
 		if os.IsNotExist(err) && !fp.Required {
-			return nil
+			return nil, nil
 		}
 
-		return err
+		return nil, err
 	}
 	defer func() {
 		if cErr := f.Close(); cErr != nil && err == nil {
@@ -111,43 +231,223 @@ func (fp *FileProvider) decode(i interface{}) (err error) {
 		}
 	}()
 
-	switch fp.FileExt {
+	return decodeContent(f, fp.FileExt)
+}
+
+// decodeContent decodes json, yaml or toml content into a map
+// Numbers of json and scalars of yaml are kept as written, to be parsed based on field types
+func decodeContent(r io.Reader, ext string) (map[string]interface{}, error) {
+	var (
+		content map[string]interface{}
+		err     error
+	)
+
+	switch ext {
 	case JSON:
-		err = json.NewDecoder(f).Decode(i)
+		d := json.NewDecoder(r)
+		d.UseNumber()
+		err = d.Decode(&content)
 
 	case YML, YAML:
-		err = yaml.NewDecoder(f).Decode(i)
+		var node yaml.Node
+		if err = yaml.NewDecoder(r).Decode(&node); err == nil {
+			content, _ = toStringMap(yamlValue(&node))
+		}
 
 	case TOML:
-		_, err = toml.DecodeReader(f, i)
+		_, err = toml.DecodeReader(r, &content)
+
+	default:
+		return nil, fmt.Errorf(unsupportedFileExtErrFormat, ErrUnsupportedFileExt, ext)
 	}
 
 	if err != nil && !errors.Is(err, io.EOF) {
-		return fmt.Errorf(decodeFailedErrFormat, err)
+		return nil, fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	return content, nil
+}
+
+// yamlValue converts a yaml node into maps, lists and scalars, keeping timestamps as written
+func yamlValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+
+		return yamlValue(node.Content[0])
+
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			// Merge keys e.g. <<: *defaults, are overridden by keys of the mapping itself
+			if key.Tag == "!!merge" {
+				for k, v := range mergedMaps(value) {
+					if _, exists := m[k]; !exists {
+						m[k] = v
+					}
+				}
+				continue
+			}
+
+			m[key.Value] = yamlValue(value)
+		}
+
+		return m
+
+	case yaml.SequenceNode:
+		list := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			list[i] = yamlValue(item)
+		}
+
+		return list
+
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return nil
+
+		case "!!bool":
+			if b, err := strconv.ParseBool(node.Value); err == nil {
+				return b
+			}
+
+		// Numbers keep their literal, while being encoded as numbers for json.Unmarshaler implementations
+		case "!!int", "!!float":
+			return json.Number(node.Value)
+		}
+
+		return node.Value
 	}
 
 	return nil
 }
 
-// provide find a value from file content based on specified key and path
-func (fp *FileProvider) provide(content map[string]interface{}, key string, path []string) (string, error) {
-	builtPath := fp.buildPath(key, path)
-	value, exists := traverseMap(content, builtPath)
+// mergedMaps returns entries of maps referenced by a yaml merge key
+func mergedMaps(node *yaml.Node) map[string]interface{} {
+	merged := make(map[string]interface{})
+	nodes := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		nodes = node.Content
+	}
+
+	for _, n := range nodes {
+		m, _ := toStringMap(yamlValue(n))
+		for k, v := range m {
+			if _, exists := merged[k]; !exists {
+				merged[k] = v
+			}
+		}
+	}
+
+	return merged
+}
+
+// formatKey returns key specified by json, yaml or toml tag based on file extension
+func (fp *FileProvider) formatKey(tags *ConfigTags) string {
+	switch fp.FileExt {
+	case JSON:
+		return tags.Json
+	case YML, YAML:
+		return tags.Yaml
+	case TOML:
+		return tags.Toml
+	}
+
+	return ""
+}
+
+// isInline reports whether fields of an embedded struct are promoted to the parent in file content
+// Embedded structs are inlined unless named by a tag, except for yaml which requires inline option
+func (fp *FileProvider) isInline(l pathLevel) bool {
+	if !l.embedded {
+		return false
+	}
+
+	switch fp.FileExt {
+	case YML, YAML:
+		return hasOption(l.tags.tag.Get("yaml"), "inline")
+	}
+
+	return fp.formatKey(l.tags) == "" && l.tags.Config == ""
+}
+
+// levelKeys returns keys of a struct field in file content, in order of precedence
+// Key of format tag is used if specified, otherwise field name followed by config tag
+func (fp *FileProvider) levelKeys(l pathLevel, name string) []string {
+	if key := fp.formatKey(l.tags); key != "" {
+		return []string{key}
+	}
+
+	if l.tags.Config != "" && !strings.EqualFold(l.tags.Config, name) {
+		return []string{name, l.tags.Config}
+	}
+
+	return []string{name}
+}
+
+// fieldKeys returns keys of each level of field path in file content, levels of inlined structs are skipped
+// Paths of slice of structs items are relative to the item e.g. servers.0.host
+func (fp *FileProvider) fieldKeys(f *Field) [][]string {
+	var keys [][]string
+	if f.parent != nil {
+		keys = append(fp.fieldKeys(f.parent), []string{f.Path[len(f.parent.Path)]})
+	}
+
+	names := f.Path[len(f.Path)-len(f.levels):]
+	for i, l := range f.levels {
+		// The field itself is never inlined, e.g. an embedded type which is not a struct
+		if i != len(f.levels)-1 && fp.isInline(l) {
+			continue
+		}
+
+		keys = append(keys, fp.levelKeys(l, names[i]))
+	}
+
+	return keys
+}
+
+// fieldPath returns path of field in file content using keys of the highest precedence
+func (fp *FileProvider) fieldPath(f *Field) []string {
+	keys := fp.fieldKeys(f)
+	path := make([]string, len(keys))
+	for i := range keys {
+		path[i] = keys[i][0]
+	}
+
+	return path
+}
+
+// provide finds a value from file content based on field keys
+func (fp *FileProvider) provide(content map[string]interface{}, f *Field) (interface{}, error) {
+	value, _, exists := traverseMap(content, fp.fieldKeys(f))
 	if !exists {
-		return "", ErrKeyNotFound
+		return nil, ErrKeyNotFound
 	}
 
 	return value, nil
 }
 
-// buildPath makes a path from key and path slice
-func (fp *FileProvider) buildPath(key string, path []string) []string {
-	newPath := make([]string, len(path))
-	copy(newPath, path)
+// sourceKey returns where value of field is found, e.g. config.yaml:Redis.Hosts
+// Keys of key value stores are joined by their separator after FilePath, e.g. app/Redis/Hosts
+func (fp *FileProvider) sourceKey(f *Field) string {
+	if fp.keySeparator != "" {
+		return fp.FilePath + strings.Join(fp.fieldPath(f), fp.keySeparator)
+	}
 
-	if key != "" {
-		newPath[len(newPath)-1] = key
+	path := fp.FilePath
+	if fp.origin != nil {
+		if origin := fp.origin(fp.fieldKeys(f)); origin != "" {
+			path = origin
+		}
 	}
 
-	return newPath
+	return path + ":" + strings.Join(fp.fieldPath(f), ".")
 }
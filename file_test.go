@@ -1,8 +1,13 @@
 package gonfig
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +19,7 @@ func TestNewFileProvider(t *testing.T) {
 	assert.Equal(t, "file.yml", fp.FilePath)
 	assert.Equal(t, ".yml", fp.FileExt)
 	assert.False(t, fp.Required)
+	assert.False(t, fp.Strict)
 }
 
 func TestFileProvider_Name(t *testing.T) {
@@ -25,29 +31,47 @@ func TestFileProvider_Name(t *testing.T) {
 }
 
 func TestFileProvider_UnmarshalStruct(t *testing.T) {
+	s := struct {
+		Custom string `config:"custom_key"`
+	}{}
+
+	fp := NewFileProvider("testdata/config.json")
+	err := fp.UnmarshalStruct(&s)
+	require.NoError(t, err)
+	assert.NotEmpty(t, s.Custom)
+
+	err = fp.UnmarshalStruct(s)
+	assert.IsType(t, &InvalidInputError{}, err)
+}
+
+func TestFileProvider_Fill(t *testing.T) {
 	t.Run("file existence", func(t *testing.T) {
+		in, err := NewInput(&struct{}{})
+		require.NoError(t, err)
+
 		fp := FileProvider{
 			FilePath: "NotExistingFile.toml",
 			FileExt:  ".toml",
 			Required: false,
 		}
 
-		var i interface{}
-		err := fp.UnmarshalStruct(i)
+		err = fp.Fill(in)
 		assert.NoError(t, err)
 
 		fp.Required = true
-		err = fp.UnmarshalStruct(i)
+		err = fp.Fill(in)
 		assert.Error(t, err)
 	})
 
 	t.Run("unsupported file extension", func(t *testing.T) {
+		in, err := NewInput(&struct{}{})
+		require.NoError(t, err)
+
 		fp := FileProvider{
 			FileExt: ".ini",
 		}
 
-		var i interface{}
-		err := fp.UnmarshalStruct(i)
+		err = fp.Fill(in)
 		require.Error(t, err)
 		assert.Truef(
 			t,
@@ -58,20 +82,20 @@ func TestFileProvider_UnmarshalStruct(t *testing.T) {
 
 	t.Run("supported file extensions", func(t *testing.T) {
 		for _, e := range []string{".json", ".yml", ".yaml", ".toml"} {
-			s := struct{}{}
+			in, err := NewInput(&struct{}{})
+			require.NoError(t, err)
+
 			fp := FileProvider{
 				FilePath: "testdata/config" + e,
 				FileExt:  e,
 				Required: true,
 			}
 
-			err := fp.UnmarshalStruct(&s)
+			err = fp.Fill(in)
 			require.NoError(t, err)
 		}
 	})
-}
 
-func TestFileProvider_Fill(t *testing.T) {
 	t.Run("should be set", func(t *testing.T) {
 		for _, e := range []string{".json", ".yml", ".yaml", ".toml"} {
 			s := struct {
@@ -119,4 +143,301 @@ func TestFileProvider_Fill(t *testing.T) {
 			}
 		}
 	})
+	t.Run("maps", func(t *testing.T) {
+		for _, e := range []string{".json", ".yml", ".yaml", ".toml"} {
+			s := struct {
+				Limits    map[string]int
+				LimitsPtr *map[string]uint `json:"limits" yaml:"limits" toml:"limits"`
+				Config    map[string]string
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+			require.NotNil(t, in)
+
+			fp := FileProvider{
+				FilePath: "testdata/config" + e,
+				FileExt:  e,
+				Required: true,
+			}
+
+			err = fp.Fill(in)
+			require.NoError(t, err)
+			for _, f := range in.Fields {
+				assert.True(t, f.IsSet)
+			}
+			assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, s.Limits)
+			require.NotNil(t, s.LimitsPtr)
+			assert.Equal(t, map[string]uint{"cpu": 2, "memory": 512}, *s.LimitsPtr)
+			assert.Equal(t, "golang.org", s.Config["host"])
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		for _, e := range []string{".json", ".toml"} {
+			s := struct {
+				Config struct {
+					Hots string
+				}
+				Custom string `json:"custom_key" toml:"custom_key"`
+				Limits map[string]int
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+			require.NotNil(t, in)
+
+			fp := FileProvider{
+				FilePath: "testdata/config" + e,
+				FileExt:  e,
+				Required: true,
+				Strict:   true,
+			}
+
+			err = fp.Fill(in)
+			require.Error(t, err)
+			ce, ok := err.(ConfigErrors)
+			require.True(t, ok)
+			require.Len(t, ce, 1)
+			assert.EqualError(t, ce[0], `unknown key "config.host", did you mean "Config.Hots"?`)
+		}
+	})
+	t.Run("slice of structs", func(t *testing.T) {
+		type server struct {
+			Host string
+			Port int
+			Tags []string
+		}
+
+		files := map[string]string{
+			".json": `{"servers": [{"host": "a.com", "port": 80, "tags": ["x", "y"]}, {"host": "b.com"}]}`,
+			".yaml": "servers:\n  - host: a.com\n    port: 80\n    tags: [x, y]\n  - host: b.com\n",
+			".toml": "[[servers]]\nhost = \"a.com\"\nport = 80\ntags = [\"x\", \"y\"]\n\n[[servers]]\nhost = \"b.com\"\n",
+		}
+		for e, content := range files {
+			path := filepath.Join(t.TempDir(), "config"+e)
+			err := ioutil.WriteFile(path, []byte(content), 0600)
+			require.NoError(t, err)
+
+			s := struct {
+				Servers []server
+				Backups [2]*server `json:"servers" yaml:"servers" toml:"servers"`
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+			require.NotNil(t, in)
+
+			fp := FileProvider{
+				FilePath: path,
+				FileExt:  e,
+				Required: true,
+				Strict:   true,
+			}
+
+			err = fp.Fill(in)
+			require.NoError(t, err)
+			assert.Equal(t, []server{{"a.com", 80, []string{"x", "y"}}, {Host: "b.com"}}, s.Servers)
+			require.NotNil(t, s.Backups[1])
+			assert.Equal(t, "b.com", s.Backups[1].Host)
+
+			fields := in.fieldsByPath()
+			require.Contains(t, fields, "Servers.1.Host")
+			assert.True(t, fields["Servers.1.Host"].IsSet)
+			assert.Equal(t, path+":Servers.1.Host", fields["Servers.1.Host"].Key)
+			assert.False(t, fields["Servers.1.Port"].IsSet)
+		}
+	})
+	t.Run("slice of structs strict", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		err := ioutil.WriteFile(path, []byte(`{"servers": [{"host": "a.com"}, {"hots": "b.com"}]}`), 0600)
+		require.NoError(t, err)
+
+		s := struct {
+			Servers [2]struct {
+				Host string
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		fp := NewFileProvider(path)
+		fp.Strict = true
+
+		err = fp.Fill(in)
+		require.Error(t, err)
+		ce, ok := err.(ConfigErrors)
+		require.True(t, ok)
+		require.Len(t, ce, 1)
+		assert.EqualError(t, ce[0], `unknown key "servers.1.hots", did you mean "Servers.1.Host"?`)
+		assert.Equal(t, "a.com", s.Servers[0].Host)
+	})
+	t.Run("keys of nested structs", func(t *testing.T) {
+		type Base struct {
+			Host string
+		}
+
+		files := map[string]string{
+			".json": `{"Host": "h1", "cache": {"Hosts": ["a", "b"]}}`,
+			".yaml": "host: h1\ncache:\n  hosts: [a, b]\n",
+			".toml": "host = \"h1\"\n[cache]\nhosts = [\"a\", \"b\"]\n",
+		}
+		for e, content := range files {
+			path := filepath.Join(t.TempDir(), "config"+e)
+			err := ioutil.WriteFile(path, []byte(content), 0600)
+			require.NoError(t, err)
+
+			s := struct {
+				Base  `yaml:",inline"`
+				Redis struct {
+					Hosts []string
+				} `json:"cache" yaml:"cache" toml:"cache"`
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+
+			fp := NewFileProvider(path)
+			fp.Strict = true
+
+			err = fp.Fill(in)
+			require.NoError(t, err, e)
+			assert.Equal(t, "h1", s.Host, e)
+			assert.Equal(t, []string{"a", "b"}, s.Redis.Hosts, e)
+			assert.Equal(t, path+":cache.Hosts", in.fieldsByPath()["Redis.Hosts"].Key, e)
+		}
+	})
+	t.Run("embedded structs", func(t *testing.T) {
+		type Base struct {
+			Host string
+		}
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		err := ioutil.WriteFile(path, []byte("base:\n  host: h1\nname: n1\n"), 0600)
+		require.NoError(t, err)
+
+		s := struct {
+			Base
+			Named *Base `json:"named"`
+			Name  string
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		// Embedded structs are not inlined in yaml without inline option
+		err = NewFileProvider(path).Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "h1", s.Host)
+		assert.Equal(t, "n1", s.Name)
+
+		path = filepath.Join(t.TempDir(), "config.json")
+		err = ioutil.WriteFile(path, []byte(`{"host": "h2", "named": {"host": "h3"}}`), 0600)
+		require.NoError(t, err)
+
+		err = NewFileProvider(path).Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "h2", s.Host)
+		require.NotNil(t, s.Named)
+		assert.Equal(t, "h3", s.Named.Host)
+	})
+	t.Run("alternative keys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		err := ioutil.WriteFile(path, []byte(`{"hostname": "h1", "redis": {"redis_hosts": ["a"]}}`), 0600)
+		require.NoError(t, err)
+
+		s := struct {
+			Host  string `config:"hostname"`
+			Redis struct {
+				Hosts []string `config:"REDIS_HOSTS"`
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		fp := NewFileProvider(path)
+		fp.Strict = true
+
+		err = fp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "h1", s.Host)
+		assert.Equal(t, []string{"a"}, s.Redis.Hosts)
+
+		// Field name takes precedence over config tag
+		err = ioutil.WriteFile(path, []byte(`{"host": "h2", "hostname": "h1"}`), 0600)
+		require.NoError(t, err)
+
+		err = NewFileProvider(path).Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "h2", s.Host)
+	})
+	t.Run("keys differing by case", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		err := ioutil.WriteFile(path, []byte(`{"HOST": "h1", "host": "h2", "hOST": "h3", "PORT": 1, "port": 2}`), 0600)
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			s := struct {
+				Host string
+				Port int `json:"port"`
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+
+			err = NewFileProvider(path).Fill(in)
+			require.NoError(t, err)
+			assert.Equal(t, "h1", s.Host)
+			assert.Equal(t, 2, s.Port)
+		}
+	})
+	t.Run("conversion", func(t *testing.T) {
+		err := os.Setenv("GONFIG_HOST", "golang.org")
+		require.NoError(t, err)
+
+		files := map[string]string{
+			".json": `{"HOST_NAME": "${GONFIG_HOST}", "tags": "a,b", "list": ["x", "y z"], "started": "2021-02-03",
+				"big": 12345678901234567, "level": "info", "version": {"major": 1, "minor": 2}}`,
+			".yaml": "HOST_NAME: ${GONFIG_HOST}\ntags: a,b\nlist: [x, y z]\nstarted: 2021-02-03\n" +
+				"big: 12345678901234567\nlevel: info\nversion: {major: 1, minor: 2}\n",
+			".toml": "HOST_NAME = \"${GONFIG_HOST}\"\ntags = \"a,b\"\nlist = [\"x\", \"y z\"]\nstarted = \"2021-02-03\"\n" +
+				"big = 12345678901234567\nlevel = \"info\"\nversion = {major = 1, minor = 2}\n",
+		}
+		for e, content := range files {
+			path := filepath.Join(t.TempDir(), "config"+e)
+			err := ioutil.WriteFile(path, []byte(content), 0600)
+			require.NoError(t, err)
+
+			s := struct {
+				Host    string   `config:"host_name" expand:"true"`
+				Tags    []string `separator:","`
+				List    []string
+				Started time.Time `format:"2006-01-02"`
+				Date    string    `config:"started"`
+				Big     int64
+				Level   logLevel
+				Version semver
+			}{}
+			in, err := NewInput(&s)
+			require.NoError(t, err)
+			require.NotNil(t, in)
+
+			fp := NewFileProvider(path)
+			fp.Strict = true
+
+			err = fp.Fill(in)
+			require.NoError(t, err, e)
+			assert.Equal(t, "golang.org", s.Host, e)
+			assert.Equal(t, []string{"a", "b"}, s.Tags, e)
+			assert.Equal(t, []string{"x", "y z"}, s.List, e)
+			assert.Equal(t, time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC), s.Started, e)
+			assert.Equal(t, "2021-02-03", s.Date, e)
+			assert.Equal(t, int64(12345678901234567), s.Big, e)
+			assert.Equal(t, logLevel(1), s.Level, e)
+			assert.Equal(t, semver{1, 2}, s.Version, e)
+		}
+	})
+}
+
+type semver struct {
+	Major, Minor int
+}
+
+func (v *semver) UnmarshalJSON(data []byte) error {
+	type plain semver
+	return json.Unmarshal(data, (*plain)(v))
 }
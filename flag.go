@@ -0,0 +1,148 @@
+package gonfig
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+// FlagProvider loads values from command line flags to provided struct
+type FlagProvider struct {
+	// Args are command line arguments to be parsed, without the program name
+	Args []string
+
+	// Prefix is used when defining flag names, defaults to ""
+	Prefix string
+
+	// FieldSeparator is used to separate field names, defaults to "-"
+	FieldSeparator string
+}
+
+var (
+	_ Provider = (*FlagProvider)(nil)
+	_ Filler   = (*FlagProvider)(nil)
+)
+
+// NewFlagProvider creates a new FlagProvider from specified arguments
+func NewFlagProvider(args []string) *FlagProvider {
+	return &FlagProvider{
+		Args:           args,
+		Prefix:         "",
+		FieldSeparator: "-",
+	}
+}
+
+// Name of provider
+func (fp *FlagProvider) Name() string {
+	return "Flag provider"
+}
+
+// Fill takes struct fields and fills their values
+// Only fields with corresponding flags passed in Args are set
+func (fp *FlagProvider) Fill(in *Input) error {
+	fs := flag.NewFlagSet(in.Name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	values := make(map[*Field]*flagValue)
+	for _, f := range in.Fields {
+		// Items of slice of structs can not be passed as flags
+		if f.Tags.Flag == ignoreCharacter || in.isStructList(f.Value.Type()) {
+			continue
+		}
+
+		fv := &flagValue{
+			name:   fp.buildName(f.Tags.Flag, f.Path),
+			isBool: isBool(f.Value.Type()),
+		}
+
+		names := []string{fv.name}
+		if f.Tags.Shorthand != "" {
+			names = append(names, f.Tags.Shorthand)
+		}
+
+		for _, name := range names {
+			if fs.Lookup(name) != nil {
+				return fmt.Errorf(flagRedefinedErrFormat, ErrFlagRedefined, name, in.getPath(f.Path))
+			}
+
+			fs.Var(fv, name, f.Tags.Description)
+		}
+
+		values[f] = fv
+	}
+
+	if err := fs.Parse(fp.Args); err != nil {
+		return err
+	}
+
+	for _, f := range in.Fields {
+		fv, exists := values[f]
+		if !exists || len(fv.values) == 0 {
+			continue
+		}
+
+		value := fv.value(in, f)
+		if err := in.SetValue(f, value); err != nil {
+			return err
+		}
+
+		f.markSet(fp.Name(), "--"+fv.name, value)
+	}
+
+	return nil
+}
+
+// buildName returns flag name from key, if not provided, path slice will be used
+func (fp *FlagProvider) buildName(key string, path []string) string {
+	if key != "" {
+		return fp.Prefix + key
+	}
+
+	names := make([]string, len(path))
+	for i := range path {
+		names[i] = strings.ReplaceAll(strings.ToLower(toSnakeCase(path[i])), "_", "-")
+	}
+
+	return fp.Prefix + strings.Join(names, fp.FieldSeparator)
+}
+
+// flagValue implements flag.Value and stores all values passed for a flag
+type flagValue struct {
+	name   string
+	values []string
+	isBool bool
+}
+
+func (fv *flagValue) String() string {
+	return strings.Join(fv.values, " ")
+}
+
+func (fv *flagValue) Set(value string) error {
+	fv.values = append(fv.values, value)
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be passed without value e.g. --verbose
+func (fv *flagValue) IsBoolFlag() bool {
+	return fv.isBool
+}
+
+// value returns the last passed value for scalar fields
+// Values of repeated flags are joined by separator for slices, arrays and maps
+func (fv *flagValue) value(in *Input, f *Field) string {
+	t := f.Value.Type()
+	for t.Kind() == reflect.Ptr && !in.isDecodable(t) {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if !in.isDecodable(t) {
+			return strings.Join(fv.values, f.Tags.Separator)
+		}
+	}
+
+	return fv.values[len(fv.values)-1]
+}
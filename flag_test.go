@@ -0,0 +1,165 @@
+package gonfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFlagProvider(t *testing.T) {
+	fp := NewFlagProvider([]string{"--host", "golang.org"})
+	require.NotNil(t, fp)
+	assert.Equal(t, []string{"--host", "golang.org"}, fp.Args)
+	assert.Equal(t, "", fp.Prefix)
+	assert.Equal(t, "-", fp.FieldSeparator)
+}
+
+func TestFlagProvider_Name(t *testing.T) {
+	fp := NewFlagProvider(nil)
+	assert.Equal(t, "Flag provider", fp.Name())
+}
+
+func TestFlagProvider_Fill(t *testing.T) {
+	t.Run("should be set", func(t *testing.T) {
+		s := struct {
+			Host    string
+			Verbose bool
+			Timeout time.Duration
+			Redis   struct {
+				Hosts    []string
+				MaxConns *int
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		fp := NewFlagProvider([]string{
+			"--host", "golang.org",
+			"--verbose",
+			"-timeout=1s",
+			"--redis-hosts", "first",
+			"--redis-hosts", "second",
+			"--redis-max-conns", "10",
+		})
+
+		err = fp.Fill(in)
+		require.NoError(t, err)
+		for _, f := range in.Fields {
+			assert.True(t, f.IsSet)
+		}
+		assert.Equal(t, "golang.org", s.Host)
+		assert.True(t, s.Verbose)
+		assert.Equal(t, time.Second, s.Timeout)
+		assert.Equal(t, []string{"first", "second"}, s.Redis.Hosts)
+		require.NotNil(t, s.Redis.MaxConns)
+		assert.Equal(t, 10, *s.Redis.MaxConns)
+
+		hosts := in.Fields[3]
+		assert.Equal(t, "Flag provider", hosts.Provider)
+		assert.Equal(t, "--redis-hosts", hosts.Key)
+		assert.Equal(t, "first second", hosts.RawValue)
+	})
+
+	t.Run("only passed flags", func(t *testing.T) {
+		s := struct {
+			Host string
+			Port int
+		}{
+			Host: "golang.org",
+		}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+		in.Fields[0].IsSet = true
+
+		fp := NewFlagProvider([]string{"--port", "8080"})
+
+		err = fp.Fill(in)
+		require.NoError(t, err)
+		assert.True(t, in.Fields[0].IsSet)
+		assert.True(t, in.Fields[1].IsSet)
+		assert.Equal(t, "golang.org", s.Host)
+		assert.Equal(t, 8080, s.Port)
+	})
+
+	t.Run("flag tag", func(t *testing.T) {
+		s := struct {
+			Hosts   []string `flag:"host,H"`
+			Ignored string   `flag:"-"`
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		fp := NewFlagProvider([]string{"--host", "first", "-H", "second"})
+
+		err = fp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, s.Hosts)
+
+		fp.Args = []string{"--ignored", "value"}
+		err = fp.Fill(in)
+		assert.Error(t, err)
+	})
+
+	t.Run("prefix and field separator", func(t *testing.T) {
+		s := struct {
+			Redis struct {
+				Host string
+			}
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		fp := FlagProvider{
+			Args:           []string{"--app.redis.host", "localhost"},
+			Prefix:         "app.",
+			FieldSeparator: ".",
+		}
+
+		err = fp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", s.Redis.Host)
+	})
+
+	t.Run("flag redefined", func(t *testing.T) {
+		s := struct {
+			Host  string
+			Other string `flag:"host"`
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		err = NewFlagProvider(nil).Fill(in)
+		require.Error(t, err)
+		assert.Truef(
+			t,
+			errors.Is(err, ErrFlagRedefined),
+			"Error must wrap ErrFlagRedefined error",
+		)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		s := struct {
+			Port int
+		}{}
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+
+		err = NewFlagProvider([]string{"--port", "http"}).Fill(in)
+		require.Error(t, err)
+		assert.Truef(
+			t,
+			errors.Is(err, ErrParsing),
+			"Error must wrap ErrParsing error",
+		)
+		assert.False(t, in.Fields[0].IsSet)
+	})
+}
@@ -0,0 +1,50 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Loader loads values into fresh values of type T, which must be a struct
+type Loader[T any] struct {
+	config *Config
+}
+
+// New creates a new Loader for struct type T configured by opts
+// Providers are applied in the order of options
+// It panics if T is not a struct, e.g. a pointer to struct, which would only fail on loading otherwise
+func New[T any](opts ...Option) *Loader[T] {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf(nonStructTypeErrFormat, t))
+	}
+
+	c := Load()
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &Loader[T]{config: c}
+}
+
+// Config returns the underlying Config, e.g. to explain values of the last call to Load
+func (l *Loader[T]) Config() *Config {
+	return l.config
+}
+
+// Load returns a new value of T populated by providers
+// The value is returned along with errors, so fields set successfully can still be inspected
+func (l *Loader[T]) Load() (T, error) {
+	var v T
+	err := l.config.Into(&v)
+	return v, err
+}
+
+// MustLoad is like Load but panics on error
+func (l *Loader[T]) MustLoad() T {
+	v, err := l.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
@@ -0,0 +1,71 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type genericConfig struct {
+	Host string `required:"true"`
+	Port int    `default:"8080"`
+}
+
+func TestNew(t *testing.T) {
+	l := New[genericConfig](WithEnv(), WithStrict())
+	require.NotNil(t, l)
+	require.NotNil(t, l.Config())
+	assert.Len(t, l.Config().Providers, 1)
+	assert.True(t, l.Config().strict)
+}
+
+func TestNew_nonStructType(t *testing.T) {
+	assert.PanicsWithValue(t, "gonfig: type argument of New must be a struct, got int", func() { New[int]() })
+	assert.PanicsWithValue(t, "gonfig: type argument of New must be a struct, got *gonfig.genericConfig", func() { New[*genericConfig]() })
+	assert.PanicsWithValue(t, "gonfig: type argument of New must be a struct, got map[string]string", func() { New[map[string]string]() })
+}
+
+func TestLoader_Load(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("HOST", "golang.org")
+	require.NoError(t, err)
+
+	l := New[genericConfig](WithEnv())
+
+	c, err := l.Load()
+	require.NoError(t, err)
+	assert.Equal(t, genericConfig{Host: "golang.org", Port: 8080}, c)
+
+	// Each call returns a fresh value
+	err = os.Setenv("PORT", "90")
+	require.NoError(t, err)
+	c2, err := l.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, 90, c2.Port)
+
+	t.Run("errors", func(t *testing.T) {
+		os.Clearenv()
+
+		c, err := New[genericConfig](WithEnv()).Load()
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequiredField))
+		assert.Equal(t, 8080, c.Port)
+	})
+
+}
+
+func TestLoader_MustLoad(t *testing.T) {
+	os.Clearenv()
+
+	assert.Panics(t, func() {
+		New[genericConfig](WithEnv()).MustLoad()
+	})
+
+	err := os.Setenv("HOST", "golang.org")
+	require.NoError(t, err)
+	assert.Equal(t, genericConfig{Host: "golang.org", Port: 8080}, New[genericConfig](WithEnv()).MustLoad())
+}
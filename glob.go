@@ -0,0 +1,134 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// GlobProvider loads values from all files matching a glob pattern to provided struct, e.g. conf.d/*.yaml
+// Files are decoded by their extension and deep merged in lexical order, so later files override earlier ones
+type GlobProvider struct {
+	// Pattern of files as accepted by filepath.Match
+	// Format specific tags are used based on extension of pattern, e.g. yaml tags for *.yaml
+	Pattern string
+
+	// Whether to report error if no file matches the pattern, defaults to false
+	Required bool
+
+	// Whether to report keys in files which are not consumed by any field, defaults to false
+	Strict bool
+}
+
+var (
+	_ Provider = (*GlobProvider)(nil)
+	_ Filler   = (*GlobProvider)(nil)
+	_ Watcher  = (*GlobProvider)(nil)
+)
+
+// NewGlobProvider creates a new GlobProvider from specified pattern
+func NewGlobProvider(pattern string) *GlobProvider {
+	return &GlobProvider{
+		Pattern:  pattern,
+		Required: false,
+		Strict:   false,
+	}
+}
+
+// Name of provider
+func (gp *GlobProvider) Name() string {
+	return "Glob provider"
+}
+
+// globFragment is the content of a file matching the pattern
+type globFragment struct {
+	path    string
+	content map[string]interface{}
+}
+
+// Fill takes struct fields and fills their values from merged content of files
+// Values are recorded as found in the last file providing them
+func (gp *GlobProvider) Fill(in *Input) error {
+	fragments, err := gp.decode()
+	if err != nil {
+		return err
+	}
+
+	content := make(map[string]interface{})
+	for _, f := range fragments {
+		mergeMaps(content, f.content)
+	}
+
+	fp := FileProvider{
+		FilePath: gp.Pattern,
+		FileExt:  filepath.Ext(gp.Pattern),
+	}
+	fp.origin = func(keys [][]string) string {
+		for i := len(fragments) - 1; i >= 0; i-- {
+			if _, _, exists := traverseMap(fragments[i].content, keys); exists {
+				return fragments[i].path
+			}
+		}
+
+		return ""
+	}
+
+	if err := fp.fill(in, in.Fields, content, gp.Name()); err != nil {
+		return err
+	}
+
+	if gp.Strict || in.strict {
+		return fp.unknownKeys(in, content)
+	}
+
+	return nil
+}
+
+// Watch sends on changed whenever a file inside directory of pattern is modified
+func (gp *GlobProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	return watchDirectory(ctx, filepath.Dir(gp.Pattern), changed)
+}
+
+// decode decodes files matching the pattern in lexical order, files with unsupported extensions are skipped
+// Errors are prefixed by path of the file failed to decode
+func (gp *GlobProvider) decode() ([]globFragment, error) {
+	matches, err := filepath.Glob(gp.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 && gp.Required {
+		return nil, fmt.Errorf(noMatchErrFormat, os.ErrNotExist, gp.Pattern)
+	}
+
+	sort.Strings(matches)
+
+	var fragments []globFragment
+	for _, path := range matches {
+		if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+
+		switch filepath.Ext(path) {
+		case JSON, YML, YAML, TOML:
+		default:
+			continue
+		}
+
+		fp := FileProvider{
+			FilePath: path,
+			FileExt:  filepath.Ext(path),
+			Required: true,
+		}
+
+		content, err := fp.decode()
+		if err != nil {
+			return nil, prefixErrors(path, err)
+		}
+
+		fragments = append(fragments, globFragment{path: path, content: content})
+	}
+
+	return fragments, nil
+}
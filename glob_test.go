@@ -0,0 +1,122 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGlobProvider(t *testing.T) {
+	gp := NewGlobProvider("conf.d/*.yaml")
+	require.NotNil(t, gp)
+	assert.Equal(t, "conf.d/*.yaml", gp.Pattern)
+	assert.Equal(t, false, gp.Required)
+	assert.Equal(t, false, gp.Strict)
+}
+
+func TestGlobProvider_Name(t *testing.T) {
+	gp := NewGlobProvider("conf.d/*.yaml")
+	assert.Equal(t, "Glob provider", gp.Name())
+}
+
+func TestGlobProvider_Fill(t *testing.T) {
+	type globConfig struct {
+		DB struct {
+			Host string
+			Port int
+			User string
+		}
+		Tags []string
+	}
+
+	t.Run("deep merge", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"10-base.yaml":     "db:\n  host: a\n  port: 1\ntags: [x, y]\n",
+			"20-override.json": `{"db": {"host": "b"}, "tags": ["z"]}`,
+			"30-user.toml":     "[db]\nuser = \"gopher\"\n",
+		})
+
+		var s globConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		gp := NewGlobProvider(filepath.Join(dir, "*"))
+		gp.Strict = true
+		err = gp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "b", s.DB.Host)
+		assert.Equal(t, 1, s.DB.Port)
+		assert.Equal(t, "gopher", s.DB.User)
+		assert.Equal(t, []string{"z"}, s.Tags)
+		assert.Equal(t, "Glob provider", in.Fields[0].Provider)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		in, err := NewInput(&globConfig{})
+		require.NoError(t, err)
+
+		gp := NewGlobProvider(filepath.Join(t.TempDir(), "*.yaml"))
+		err = gp.Fill(in)
+		assert.NoError(t, err)
+
+		gp.Required = true
+		err = gp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"10-good.yaml": "db:\n  host: a\n",
+			"20-bad.yaml":  "db: [\n",
+		})
+
+		in, err := NewInput(&globConfig{})
+		require.NoError(t, err)
+
+		err = NewGlobProvider(filepath.Join(dir, "*.yaml")).Fill(in)
+		require.Error(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), filepath.Join(dir, "20-bad.yaml")+": failed to decode"), err.Error())
+	})
+
+	t.Run("source file of values", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"10-base.yaml":     "db:\n  host: a\n  port: 1\n",
+			"20-override.json": `{"db": {"host": "b"}}`,
+		})
+
+		in, err := NewInput(&globConfig{})
+		require.NoError(t, err)
+
+		err = NewGlobProvider(filepath.Join(dir, "*")).Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "20-override.json")+":DB.Host", in.Fields[0].Key)
+		assert.Equal(t, filepath.Join(dir, "10-base.yaml")+":DB.Port", in.Fields[1].Key)
+	})
+
+	t.Run("unsupported fragment", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"10-app.ini": "host=a",
+			"20-db.yaml": "db:\n  host: b\n",
+			"README.md":  "# conf.d",
+		})
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "30-dir.yaml"), 0o755))
+
+		var s globConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		err = NewGlobProvider(filepath.Join(dir, "*")).Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "b", s.DB.Host)
+	})
+}
@@ -1,6 +1,6 @@
 module github.com/miladabc/gonfig
 
-go 1.15
+go 1.18
 
 require (
 	github.com/BurntSushi/toml v0.3.1
@@ -8,3 +8,8 @@ require (
 	github.com/stretchr/testify v1.6.1
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
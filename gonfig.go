@@ -2,8 +2,10 @@ package gonfig
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 )
 
 // Config loads values from specified providers into given struct
@@ -12,10 +14,26 @@ type Config struct {
 	// If multiple values are provided for a field, last one will get applied
 	Providers []Provider
 
-	// Collection of errors during loading values into provided struct
-	ce ConfigErrors
+	// mu guards values replaced by Watch, along with in
+	mu sync.RWMutex
+
+	// Input of last successful reload or call to Into, used to explain where values came from
+	in *Input
+
+	// Custom decoders registered for field types
+	decoders map[reflect.Type]DecodeFunc
+
+	// Whether to report unknown keys found by providers
+	strict bool
+
+	// Profiles activated explicitly, or listed in profileEnv
+	profiles   []string
+	profileEnv string
 }
 
+// DecodeFunc converts a string into a value of the type it is registered for
+type DecodeFunc func(value string) (interface{}, error)
+
 // Load creates a new Config object
 func Load() *Config {
 	return &Config{}
@@ -26,16 +44,126 @@ func (c *Config) FromEnv() *Config {
 	return c.AddProvider(NewEnvProvider())
 }
 
+// FromFlags adds a FlagProvider to Providers list
+// Command line arguments are taken from os.Args
+func (c *Config) FromFlags() *Config {
+	return c.AddProvider(NewFlagProvider(os.Args[1:]))
+}
+
 // FromFile adds a FileProvider to Providers list
 // In case of .env file, it adds a EnvProvider to the list
 func (c *Config) FromFile(path string) *Config {
-	if filepath.Ext(path) == ENV {
-		ep := NewEnvProvider()
-		ep.Source = path
-		return c.AddProvider(NewEnvProvider())
+	return c.AddProvider(newFileProvider(path))
+}
+
+// FromGlob adds a GlobProvider to Providers list
+// Files matching pattern are deep merged in lexical order, e.g. conf.d/*.yaml
+func (c *Config) FromGlob(pattern string) *Config {
+	return c.AddProvider(NewGlobProvider(pattern))
+}
+
+// FromFileSearch adds a FileSearchProvider to Providers list, which loads the first file found
+// name is looked up with any supported extension, in dirs or in default search path if not specified
+func (c *Config) FromFileSearch(name string, dirs ...string) *Config {
+	return c.AddProvider(NewFileSearchProvider(name, dirs...))
+}
+
+// FromFileSearchAll adds a FileSearchProvider to Providers list, which loads all files found
+// Files found earlier in the search path take precedence
+func (c *Config) FromFileSearchAll(name string, dirs ...string) *Config {
+	sp := NewFileSearchProvider(name, dirs...)
+	sp.All = true
+	return c.AddProvider(sp)
+}
+
+// UsedFiles returns files found by FileSearchProviders during the last call to Into
+func (c *Config) UsedFiles() []string {
+	var files []string
+	for _, p := range c.Providers {
+		if sp, ok := p.(*FileSearchProvider); ok {
+			files = append(files, sp.UsedFiles()...)
+		}
 	}
 
-	return c.AddProvider(NewFileProvider(path))
+	return files
+}
+
+// FromDirectory adds a DirectoryProvider to Providers list
+// If dir is empty, $CREDENTIALS_DIRECTORY set by systemd is used
+func (c *Config) FromDirectory(dir string) *Config {
+	return c.AddProvider(NewDirectoryProvider(dir))
+}
+
+// FromConsul adds a ConsulProvider to Providers list for keys under prefix
+// Address and ACL token are taken from $CONSUL_HTTP_ADDR and $CONSUL_HTTP_TOKEN
+func (c *Config) FromConsul(prefix string) *Config {
+	return c.AddProvider(NewConsulProvider(prefix))
+}
+
+// FromEtcd adds an EtcdProvider to Providers list for keys under prefix
+// Endpoint of etcd JSON gateway is taken from $ETCD_ENDPOINT
+func (c *Config) FromEtcd(prefix string) *Config {
+	return c.AddProvider(NewEtcdProvider(prefix))
+}
+
+// FromVault adds a VaultProvider to Providers list for secret at path, along with secrets referenced by vault tags
+// Address and token are taken from $VAULT_ADDR and $VAULT_TOKEN
+func (c *Config) FromVault(path string) *Config {
+	return c.AddProvider(NewVaultProvider(path))
+}
+
+// FromSSM adds an SSMProvider to Providers list for parameters under path of AWS SSM Parameter Store
+// Region and credentials are taken from env vars, or credentials of ECS task role
+func (c *Config) FromSSM(path string) *Config {
+	return c.AddProvider(NewSSMProvider(path))
+}
+
+// FromHTTP adds an HTTPProvider to Providers list for content served at url
+// Content is decoded based on Content-Type of response or extension of url
+func (c *Config) FromHTTP(url string) *Config {
+	return c.AddProvider(NewHTTPProvider(url))
+}
+
+// Profile activates profiles, applied in the specified order
+// Each file is followed by its <name>.<profile>.<ext> overlay if exists, e.g. config.prod.yaml
+// and default.<profile> tags take precedence over default tag
+func (c *Config) Profile(names ...string) *Config {
+	c.profiles = append(c.profiles, names...)
+	return c
+}
+
+// ProfileFromEnv activates comma separated profiles listed in env var key, e.g. APP_PROFILE=prod
+// The env var is read on loading values, profiles activated by Profile take precedence
+func (c *Config) ProfileFromEnv(key string) *Config {
+	c.profileEnv = key
+	return c
+}
+
+// Profiles returns active profiles
+func (c *Config) Profiles() []string {
+	if len(c.profiles) != 0 || c.profileEnv == "" {
+		return c.profiles
+	}
+
+	return extractItems(os.Getenv(c.profileEnv), ",")
+}
+
+// Strict enables strict mode for all providers supporting it
+// Keys which are not consumed by any field are reported as errors, e.g. typos in files or prefixed env vars
+func (c *Config) Strict() *Config {
+	c.strict = true
+	return c
+}
+
+// RegisterDecoder registers a custom decoder for fields of type t
+// Decoders take precedence over builtin parsers and encoding.TextUnmarshaler implementations
+func (c *Config) RegisterDecoder(t reflect.Type, fn DecodeFunc) *Config {
+	if c.decoders == nil {
+		c.decoders = make(map[reflect.Type]DecodeFunc)
+	}
+
+	c.decoders[t] = fn
+	return c
 }
 
 // AddProvider adds a Provider to Providers list
@@ -48,33 +176,80 @@ func (c *Config) AddProvider(p Provider) *Config {
 // and validate final struct for required and default fields
 // If multiple values are provided for a field, last one will get applied
 func (c *Config) Into(i interface{}) error {
-	in, err := NewInput(i)
+	in, ce, err := c.load(i)
 	if err != nil {
 		return err
 	}
 
-	for _, p := range c.Providers {
+	c.mu.Lock()
+	c.in = in
+	c.mu.Unlock()
+
+	if len(ce) != 0 {
+		return ce
+	}
+
+	return nil
+}
+
+// RLock locks values replaced by Watch for reading, they are not replaced until RUnlock is called
+// It is only needed for reading the struct passed to Watch while it is running
+func (c *Config) RLock() {
+	c.mu.RLock()
+}
+
+// RUnlock undoes a single RLock call
+func (c *Config) RUnlock() {
+	c.mu.RUnlock()
+}
+
+// load applies providers to i and returns its Input along with collected errors, without modifying c
+func (c *Config) load(i interface{}) (*Input, ConfigErrors, error) {
+	in, err := newInput(i, c.decoders)
+	if err != nil {
+		return nil, nil, err
+	}
+	profiles := c.Profiles()
+	in.strict = c.strict
+	in.profiles = profiles
+
+	var ce ConfigErrors
+	for _, p := range c.providers(profiles) {
+		// UnmarshalStruct of FileProvider is a deprecated wrapper of Fill, so files are decoded once
 		if u, ok := p.(Unmarshaler); ok {
-			if err := u.UnmarshalStruct(i); err != nil {
-				c.collectError(fmt.Errorf("%v: %w", p.Name(), err))
+			if _, isFile := p.(*FileProvider); !isFile {
+				if err := u.UnmarshalStruct(i); err != nil {
+					ce.collectProviderError(p, err)
+				}
 			}
 		}
 
 		if f, ok := p.(Filler); ok {
+			unset := unsetFields(in)
 			if err := f.Fill(in); err != nil {
-				c.collectError(fmt.Errorf("%v: %w", p.Name(), err))
+				ce.collectProviderError(p, err)
+			}
+
+			// Custom providers may only mark fields as set
+			for _, f := range unset {
+				if f.IsSet && f.Provider == "" {
+					f.Provider = p.Name()
+				}
 			}
 		}
 	}
 
-	for _, f := range in.Fields {
+	all := in.allFields()
+	for _, f := range all {
 		if !f.IsSet {
 			if f.Tags.Required {
-				c.collectError(fmt.Errorf(requiredFieldErrFormat, ErrRequiredField, in.getPath(f.Path)))
-			} else if f.Tags.Default != "" {
-				err := in.SetValue(f, f.Tags.Default)
+				ce.collect(fmt.Errorf(requiredFieldErrFormat, ErrRequiredField, in.getPath(f.Path)))
+			} else if value, tag := f.Tags.profileDefault(profiles); value != "" {
+				err := in.SetValue(f, value)
 				if err != nil {
-					c.collectError(err)
+					ce.collect(err)
+				} else {
+					f.setSource(defaultProviderName, tag, value)
 				}
 			}
 		}
@@ -82,18 +257,90 @@ func (c *Config) Into(i interface{}) error {
 		if f.Tags.Expand && f.Value.Kind() == reflect.String {
 			err := in.SetValue(f, f.Value.String())
 			if err != nil {
-				c.collectError(err)
+				ce.collect(err)
 			}
 		}
 	}
 
-	if len(c.ce) != 0 {
-		return c.ce
+	fields := in.fieldsByPath()
+	for _, f := range all {
+		for _, err := range in.validate(f) {
+			ce.collect(err)
+		}
+
+		for _, err := range in.validateRelations(f, fields) {
+			ce.collect(err)
+		}
 	}
 
-	return nil
+	for _, err := range in.callValidators() {
+		ce.collect(err)
+	}
+
+	return in, ce, nil
 }
 
-func (c *Config) collectError(e error) {
-	c.ce = append(c.ce, e)
+// collect appends e to collection of errors
+func (ce *ConfigErrors) collect(e error) {
+	*ce = append(*ce, e)
+}
+
+// collectProviderError prefixes errors with provider name, collection of errors are flattened
+func (ce *ConfigErrors) collectProviderError(p Provider, err error) {
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		errs = ConfigErrors{err}
+	}
+
+	for _, e := range errs {
+		ce.collect(fmt.Errorf("%v: %w", p.Name(), e))
+	}
+}
+
+// providers returns Providers where each FileProvider is followed by its overlays of profiles
+// FileSearchProviders load overlays of files they find, by profiles of Input
+func (c *Config) providers(profiles []string) []Provider {
+	providers := make([]Provider, 0, len(c.Providers))
+	for _, p := range c.Providers {
+		providers = append(providers, p)
+
+		switch p := p.(type) {
+		case *FileProvider:
+			for _, profile := range profiles {
+				providers = append(providers, p.overlay(profile))
+			}
+		case *EnvProvider:
+			// Env vars of OS have no overlay
+			if p.Source != "" {
+				for _, profile := range profiles {
+					providers = append(providers, p.overlay(profile))
+				}
+			}
+		}
+	}
+
+	return providers
+}
+
+// newFileProvider returns a FileProvider for path, or an EnvProvider in case of .env file
+func newFileProvider(path string) Provider {
+	if filepath.Ext(path) == ENV {
+		ep := NewEnvProvider()
+		ep.Source = path
+		return ep
+	}
+
+	return NewFileProvider(path)
+}
+
+// unsetFields returns fields which are not set yet
+func unsetFields(in *Input) []*Field {
+	var fields []*Field
+	for _, f := range in.allFields() {
+		if !f.IsSet {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
 }
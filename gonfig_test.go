@@ -3,6 +3,9 @@ package gonfig
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,6 +25,14 @@ func TestConfig_FromEnv(t *testing.T) {
 	assert.IsType(t, new(EnvProvider), c.Providers[0])
 }
 
+func TestConfig_FromFlags(t *testing.T) {
+	c := Config{}
+
+	c.FromFlags()
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(FlagProvider), c.Providers[0])
+}
+
 func TestConfig_FromFile(t *testing.T) {
 	t.Run("json-yaml-toml files", func(t *testing.T) {
 		c := Config{}
@@ -37,9 +48,181 @@ func TestConfig_FromFile(t *testing.T) {
 		c.FromFile("file.env")
 		require.Len(t, c.Providers, 1)
 		assert.IsType(t, new(EnvProvider), c.Providers[0])
+		assert.Equal(t, "file.env", c.Providers[0].(*EnvProvider).Source)
 	})
 }
 
+func TestConfig_FromGlob(t *testing.T) {
+	c := Config{}
+
+	c.FromGlob("conf.d/*.yaml")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(GlobProvider), c.Providers[0])
+	assert.Equal(t, "conf.d/*.yaml", c.Providers[0].(*GlobProvider).Pattern)
+}
+
+func TestConfig_FromFileSearch(t *testing.T) {
+	c := Config{}
+
+	c.FromFileSearch("app", "/etc/app").FromFileSearchAll("app")
+	require.Len(t, c.Providers, 2)
+	require.IsType(t, new(FileSearchProvider), c.Providers[0])
+	assert.Equal(t, []string{"/etc/app"}, c.Providers[0].(*FileSearchProvider).Dirs)
+	assert.False(t, c.Providers[0].(*FileSearchProvider).All)
+	require.IsType(t, new(FileSearchProvider), c.Providers[1])
+	assert.True(t, c.Providers[1].(*FileSearchProvider).All)
+}
+
+func TestConfig_FromDirectory(t *testing.T) {
+	c := Config{}
+
+	c.FromDirectory("/run/secrets")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(DirectoryProvider), c.Providers[0])
+	assert.Equal(t, "/run/secrets", c.Providers[0].(*DirectoryProvider).Dir)
+}
+
+func TestConfig_FromConsul(t *testing.T) {
+	c := Config{}
+
+	c.FromConsul("app")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(ConsulProvider), c.Providers[0])
+	assert.Equal(t, "app", c.Providers[0].(*ConsulProvider).Prefix)
+}
+
+func TestConfig_FromEtcd(t *testing.T) {
+	c := Config{}
+
+	c.FromEtcd("/app/")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(EtcdProvider), c.Providers[0])
+	assert.Equal(t, "/app/", c.Providers[0].(*EtcdProvider).Prefix)
+}
+
+func TestConfig_FromVault(t *testing.T) {
+	c := Config{}
+
+	c.FromVault("secret/data/app")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(VaultProvider), c.Providers[0])
+	assert.Equal(t, "secret/data/app", c.Providers[0].(*VaultProvider).Path)
+}
+
+func TestConfig_FromSSM(t *testing.T) {
+	c := Config{}
+
+	c.FromSSM("/app/prod")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(SSMProvider), c.Providers[0])
+	assert.Equal(t, "/app/prod", c.Providers[0].(*SSMProvider).Path)
+}
+
+func TestConfig_FromHTTP(t *testing.T) {
+	c := Config{}
+
+	c.FromHTTP("https://config.internal/app.json")
+	require.Len(t, c.Providers, 1)
+	assert.IsType(t, new(HTTPProvider), c.Providers[0])
+	assert.Equal(t, "https://config.internal/app.json", c.Providers[0].(*HTTPProvider).URL)
+}
+
+func TestConfig_Profile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml":         "host: localhost\nport: 80\n",
+		"config.staging.yaml": "host: staging.golang.org\n",
+		"config.prod.yaml":    "host: golang.org\n",
+	})
+
+	type profileConfig struct {
+		Host    string
+		Port    int
+		Timeout string `default:"5s" default.prod:"1s"`
+		Debug   bool   `default:"true" default.staging:"true" default.prod:"false"`
+	}
+
+	t.Run("explicit", func(t *testing.T) {
+		var s profileConfig
+		c := Load().FromFile(filepath.Join(dir, "config.yaml")).Profile("staging", "prod")
+		err := c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"staging", "prod"}, c.Profiles())
+		assert.Equal(t, profileConfig{"golang.org", 80, "1s", false}, s)
+		assert.Contains(t, c.Explain(), "default tag           default.prod\n")
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		os.Clearenv()
+		err := os.Setenv("APP_PROFILE", "staging")
+		require.NoError(t, err)
+
+		var s profileConfig
+		c := Load().FromFile(filepath.Join(dir, "config.yaml")).ProfileFromEnv("APP_PROFILE")
+		err = c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, profileConfig{"staging.golang.org", 80, "5s", true}, s)
+
+		c.Profile("missing")
+		s = profileConfig{}
+		err = c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, profileConfig{"localhost", 80, "5s", true}, s)
+	})
+
+	t.Run("env file", func(t *testing.T) {
+		os.Clearenv()
+		writeFiles(t, dir, map[string]string{
+			"config.env":      "HOST=localhost\nPORT=80\n",
+			"config.prod.env": "HOST=golang.org\n",
+		})
+
+		var s profileConfig
+		c := Load().FromFile(filepath.Join(dir, "config.env")).Profile("prod")
+		err := c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, profileConfig{"golang.org", 80, "1s", false}, s)
+		assert.Contains(t, c.Explain(), filepath.Join(dir, "config.prod.env")+":HOST")
+	})
+}
+
+func TestConfig_Strict(t *testing.T) {
+	s := struct {
+		Config struct {
+			Host string
+		}
+	}{}
+
+	err := Load().FromFile("testdata/config.json").Strict().Into(&s)
+	require.Error(t, err)
+	ce := err.(ConfigErrors)
+	require.Len(t, ce, 3)
+	assert.EqualError(t, ce[0], `File provider (json): unknown key "custom_key"`)
+	assert.EqualError(t, ce[1], `File provider (json): unknown key "limits.cpu"`)
+	assert.EqualError(t, ce[2], `File provider (json): unknown key "limits.memory"`)
+	assert.Equal(t, "golang.org", s.Config.Host)
+}
+
+func TestConfig_RegisterDecoder(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("PATTERN", "^go")
+	require.NoError(t, err)
+
+	s := struct {
+		Pattern *regexp.Regexp
+	}{}
+
+	err = Load().
+		RegisterDecoder(reflect.TypeOf(s.Pattern), func(value string) (interface{}, error) {
+			return regexp.Compile(value)
+		}).
+		FromEnv().
+		Into(&s)
+	require.NoError(t, err)
+	require.NotNil(t, s.Pattern)
+	assert.Equal(t, "^go", s.Pattern.String())
+}
+
 func TestConfig_AddProvider(t *testing.T) {
 	c := Config{}
 
@@ -70,3 +253,85 @@ func TestConfig_Into(t *testing.T) {
 	assert.Equal(t, "default_value", s.Default)
 	assert.Equal(t, "expand_value", s.Expand)
 }
+
+type probeProvider struct {
+	unmarshaled, filled int
+}
+
+func (pp *probeProvider) Name() string {
+	return "probe provider"
+}
+
+func (pp *probeProvider) UnmarshalStruct(i interface{}) error {
+	pp.unmarshaled++
+	return nil
+}
+
+func (pp *probeProvider) Fill(in *Input) error {
+	pp.filled++
+	return nil
+}
+
+func TestConfig_Into_unmarshalerAndFiller(t *testing.T) {
+	var s struct {
+		Host string
+	}
+
+	pp := &probeProvider{}
+	err := Load().AddProvider(pp).Into(&s)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pp.unmarshaled)
+	assert.Equal(t, 1, pp.filled)
+}
+
+func TestConfig_Into_sliceOfStructs(t *testing.T) {
+	os.Clearenv()
+	for k, v := range map[string]string{
+		"SERVERS_0_HOST": "a.com",
+		"SERVERS_1_PORT": "90",
+	} {
+		err := os.Setenv(k, v)
+		require.NoError(t, err)
+	}
+
+	type server struct {
+		Host string `required:"true"`
+		Port int    `default:"8080" min:"1"`
+	}
+	s := struct {
+		Servers []server `min:"1"`
+	}{}
+
+	err := Load().FromEnv().Into(&s)
+	require.Error(t, err)
+	ce := err.(ConfigErrors)
+	require.Len(t, ce, 1)
+	assert.True(t, errors.Is(ce[0], ErrRequiredField))
+	assert.Contains(t, ce[0].Error(), "Servers.1.Host")
+	assert.Equal(t, []server{{"a.com", 8080}, {"", 90}}, s.Servers)
+}
+
+func TestConfig_Into_merge(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("CORS_ORIGINS", "https://admin.example.com https://app.example.com")
+	require.NoError(t, err)
+	err = os.Setenv("LIMITS", "upload:10")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "cors:\n  origins: [https://app.example.com]\nlimits:\n  api: 100\n",
+	})
+
+	s := struct {
+		CORS struct {
+			Origins []string `merge:"unique"`
+		}
+		Limits map[string]int `merge:"deep"`
+	}{}
+
+	err = Load().FromFile(filepath.Join(dir, "config.yaml")).FromEnv().Into(&s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, s.CORS.Origins)
+	assert.Equal(t, map[string]int{"api": 100, "upload": 10}, s.Limits)
+}
@@ -0,0 +1,243 @@
+package gonfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentTypes maps media types of responses to file extensions of decoders
+var contentTypes = map[string]string{
+	"application/json":   JSON,
+	"text/json":          JSON,
+	"application/yaml":   YAML,
+	"application/x-yaml": YAML,
+	"text/yaml":          YAML,
+	"text/x-yaml":        YAML,
+	"application/toml":   TOML,
+	"text/toml":          TOML,
+}
+
+// HTTPProvider loads values from content served over HTTP(S) to provided struct
+// Content is decoded the same as files, based on Content-Type of response or extension of URL
+type HTTPProvider struct {
+	// URL of content
+	URL string
+
+	// Format is the file extension used for decoding content, e.g. ".yaml"
+	// Defaults to "", which detects it from Content-Type of response, falling back to extension of URL
+	Format string
+
+	// Headers are sent along with requests
+	Headers http.Header
+
+	// BearerToken is sent in Authorization header if specified
+	BearerToken string
+
+	// Timeout of requests, defaults to 10s
+	Timeout time.Duration
+
+	// PollInterval is used by Watch to check content for changes, defaults to 30s
+	PollInterval time.Duration
+
+	// Whether to report error if content is not found, defaults to false
+	Required bool
+
+	// Whether to report keys which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Client is used for sending requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	// Last content fetched, reused if not modified according to ETag and Last-Modified headers
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	content      map[string]interface{}
+	ext          string
+	sum          [sha256.Size]byte
+}
+
+var (
+	_ Provider = (*HTTPProvider)(nil)
+	_ Filler   = (*HTTPProvider)(nil)
+	_ Watcher  = (*HTTPProvider)(nil)
+)
+
+// NewHTTPProvider creates a new HTTPProvider from specified URL
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{
+		URL:          url,
+		Format:       "",
+		Timeout:      remoteTimeout,
+		PollInterval: 30 * time.Second,
+		Required:     false,
+		Strict:       false,
+	}
+}
+
+// Name of provider
+func (hp *HTTPProvider) Name() string {
+	return "HTTP provider"
+}
+
+// Fill takes struct fields and fills their values from content of URL
+func (hp *HTTPProvider) Fill(in *Input) error {
+	content, ext, _, err := hp.load(context.Background())
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+
+	fp := FileProvider{
+		FilePath: redactURL(hp.URL),
+		FileExt:  ext,
+	}
+
+	if err := fp.fill(in, in.Fields, content, hp.Name()); err != nil {
+		return err
+	}
+
+	if hp.Strict || in.strict {
+		return fp.unknownKeys(in, content)
+	}
+
+	return nil
+}
+
+// Watch sends on changed whenever content of URL is modified, checking it every PollInterval
+// Failed requests are ignored, as reloading would fail anyway
+func (hp *HTTPProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	interval := hp.PollInterval
+	if interval <= 0 {
+		interval = pollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if _, _, modified, err := hp.load(ctx); err == nil && modified {
+				notify(changed)
+			}
+		}
+	}
+}
+
+// load fetches and decodes content of URL along with its extension
+// Conditional requests are sent if content is fetched before, and the previous content is returned if not modified
+// modified reports whether content is different from the previous one
+func (hp *HTTPProvider) load(ctx context.Context) (content map[string]interface{}, ext string, modified bool, err error) {
+	timeout := hp.Timeout
+	if timeout <= 0 {
+		timeout = remoteTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hp.URL, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	for name, values := range hp.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if hp.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+hp.BearerToken)
+	}
+
+	hp.mu.Lock()
+	if hp.content != nil {
+		if hp.etag != "" {
+			req.Header.Set("If-None-Match", hp.etag)
+		}
+		if hp.lastModified != "" {
+			req.Header.Set("If-Modified-Since", hp.lastModified)
+		}
+	}
+	hp.mu.Unlock()
+
+	body, header, err := fetch(hp.Client, req)
+	switch {
+	case errors.Is(err, errNotModified):
+		hp.mu.Lock()
+		defer hp.mu.Unlock()
+		return hp.content, hp.ext, false, nil
+
+	case errors.Is(err, ErrKeyNotFound) && !hp.Required:
+		return nil, "", false, nil
+
+	case err != nil:
+		return nil, "", false, err
+	}
+
+	ext = hp.format(header)
+	content, err = decodeContent(bytes.NewReader(body), ext)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if content == nil {
+		content = make(map[string]interface{})
+	}
+
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	sum := sha256.Sum256(body)
+	modified = sum != hp.sum
+	hp.etag, hp.lastModified = header.Get("ETag"), header.Get("Last-Modified")
+	hp.content, hp.ext, hp.sum = content, ext, sum
+
+	return content, ext, modified, nil
+}
+
+// format returns Format, or the extension matching Content-Type of response or URL
+func (hp *HTTPProvider) format(header http.Header) string {
+	if hp.Format != "" {
+		return "." + strings.TrimPrefix(hp.Format, ".")
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		if ext, exists := contentTypes[mediaType]; exists {
+			return ext
+		}
+		if strings.HasSuffix(mediaType, "+json") {
+			return JSON
+		}
+	}
+
+	if u, err := url.Parse(hp.URL); err == nil {
+		return path.Ext(u.Path)
+	}
+
+	return ""
+}
+
+// redactURL returns u with password replaced, used for reporting where values are found
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	return parsed.Redacted()
+}
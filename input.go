@@ -1,6 +1,8 @@
 package gonfig
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -17,6 +19,18 @@ type Input struct {
 
 	// Fields information
 	Fields []*Field
+
+	// Nested structs along with the root struct, ordered from the deepest, used to call Validators
+	structs []*Field
+
+	// Custom decoders registered for field types
+	decoders map[reflect.Type]DecodeFunc
+
+	// Whether providers supporting strict mode should report unknown keys
+	strict bool
+
+	// Active profiles of Config loading values
+	profiles []string
 }
 
 // Field information
@@ -24,7 +38,7 @@ type Field struct {
 	// Field value
 	Value reflect.Value
 
-	// Field tags
+	// Field tags, shared between inputs of the same struct type so they must not be modified
 	Tags *ConfigTags
 
 	// Slice of field names from root of struct all the way down to the field
@@ -32,11 +46,55 @@ type Field struct {
 
 	// IsSet specifies whether field value is set by one of the providers
 	IsSet bool
+
+	// Provider is the name of provider which set the field value
+	Provider string
+
+	// Key is where the value is found by provider, e.g. env var name or file path and key
+	Key string
+
+	// RawValue is the value provided before being converted to field type
+	RawValue string
+
+	// embedded specifies whether field is an embedded struct
+	embedded bool
+
+	// secret specifies whether value is provided by a secret store, it is redacted the same as secret fields
+	secret bool
+
+	// levels are struct fields along Path, relative to the item for fields of slice of structs items
+	levels []pathLevel
+
+	// items holds fields of each item of a slice or array of structs, created by providers
+	items []*Input
+
+	// parent is the slice or array of structs which the field belongs to one of its items
+	parent *Field
+
+	// prefixes of enclosing structs specified by prefix tag, ordered from the outermost
+	prefixes []structPrefix
+}
+
+// pathLevel is a struct field along the path of a field, used for building keys from tags of each level
+type pathLevel struct {
+	tags     *ConfigTags
+	embedded bool
+}
+
+// structPrefix is the prefix tag of a nested struct along with the length of its path
+type structPrefix struct {
+	depth int
+	value string
 }
 
 // NewInput validates and returns a new Input with all settable fields
 // Input argument must be a non-nil struct pointer
 func NewInput(i interface{}) (*Input, error) {
+	return newInput(i, nil)
+}
+
+// newInput returns a new Input which uses decoders for setting values of corresponding types
+func newInput(i interface{}, decoders map[reflect.Type]DecodeFunc) (*Input, error) {
 	v := reflect.ValueOf(i)
 
 	if err := validateInput(v); err != nil {
@@ -44,7 +102,8 @@ func NewInput(i interface{}) (*Input, error) {
 	}
 
 	in := Input{
-		Name: v.Type().String(),
+		Name:     v.Type().String(),
+		decoders: decoders,
 	}
 
 	f := Field{
@@ -52,7 +111,7 @@ func NewInput(i interface{}) (*Input, error) {
 		Tags:  new(ConfigTags),
 	}
 
-	if err := in.traverseField(&f); err != nil {
+	if err := in.traverse(&f); err != nil {
 		return nil, err
 	}
 
@@ -74,55 +133,150 @@ func validateInput(v reflect.Value) error {
 	return nil
 }
 
-// traverseField recursively traverse all fields and collect their information
-func (in *Input) traverseField(f *Field) error {
-	if !f.Value.CanSet() || f.Tags.Ignore {
-		return nil
+// markSet marks field as set and records where its value came from
+func (f *Field) markSet(provider, key, value string) {
+	f.IsSet = true
+	f.setSource(provider, key, value)
+}
+
+// setSource records where the field value came from, secret values are redacted
+func (f *Field) setSource(provider, key, value string) {
+	f.Provider = provider
+	f.Key = key
+	f.RawValue = fmt.Sprint(redact(f, value))
+}
+
+func (in *Input) collectField(f *Field) {
+	in.Fields = append(in.Fields, f)
+}
+
+// allFields returns fields along with fields of slice of structs items
+func (in *Input) allFields() []*Field {
+	fields := make([]*Field, 0, len(in.Fields))
+	for _, f := range in.Fields {
+		fields = append(fields, f)
+		for _, item := range f.items {
+			fields = append(fields, item.allFields()...)
+		}
 	}
 
-	if err := in.isSupportedType(f.Value.Type()); err != nil {
-		return fmt.Errorf(badFieldErrFormat, in.getPath(f.Path), err)
+	return fields
+}
+
+// allStructs returns nested structs along with structs of slice of structs items, ordered from the deepest
+func (in *Input) allStructs() []*Field {
+	var structs []*Field
+	for _, f := range in.Fields {
+		for _, item := range f.items {
+			structs = append(structs, item.allStructs()...)
+		}
 	}
 
-	if isStruct(f.Value.Type()) {
-		for i := 0; i < f.Value.NumField(); i++ {
-			nestedField := Field{
-				Value: f.Value.Field(i),
-				Tags:  extractTags(f.Value.Type().Field(i).Tag),
-				Path:  append(f.Path, f.Value.Type().Field(i).Name),
-			}
+	return append(structs, in.structs...)
+}
 
-			if err := in.traverseField(&nestedField); err != nil {
-				return err
-			}
+// isStructList reports whether type is a slice or array of structs, which items are filled field by field
+func (in *Input) isStructList(t reflect.Type) bool {
+	if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || in.isDecodable(t) {
+		return false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr && !in.isDecodable(elem) {
+		elem = elem.Elem()
+	}
+
+	return in.isNested(elem)
+}
+
+// setItems sets a slice or array of structs to n zero items and collects fields of each item
+// Previous items are discarded, so each provider replaces the whole list
+func (in *Input) setItems(f *Field, n int) error {
+	t := f.Value.Type()
+	if t.Kind() == reflect.Array {
+		if n > t.Len() {
+			return fmt.Errorf(overflowErrFormat, ErrValueOverflow, n, t, in.getPath(f.Path))
 		}
 
-		return nil
+		f.Value.Set(reflect.Zero(t))
+	} else {
+		f.Value.Set(reflect.MakeSlice(t, n, n))
 	}
 
-	if f.Value.Kind() == reflect.Ptr && isStruct(f.Value.Type().Elem()) {
-		if f.Value.IsNil() {
-			initPtr(f.Value)
+	f.items = make([]*Input, n)
+	for i := range f.items {
+		item := Input{
+			Name:     in.Name,
+			decoders: in.decoders,
+			strict:   in.strict,
 		}
 
-		pointedField := Field{
-			Value: f.Value.Elem(),
-			Tags:  f.Tags,
-			Path:  f.Path,
+		itemField := Field{
+			Value: f.Value.Index(i),
+			Tags:  new(ConfigTags),
+			Path:  appendPath(f.Path, strconv.Itoa(i)),
+		}
+
+		if err := item.traverse(&itemField); err != nil {
+			return err
 		}
 
-		return in.traverseField(&pointedField)
+		for _, field := range item.Fields {
+			field.parent = f
+		}
+
+		f.items[i] = &item
 	}
 
-	in.collectField(f)
 	return nil
 }
 
-func (in *Input) collectField(f *Field) {
-	in.Fields = append(in.Fields, f)
+// isSupportedMerge checks merge tag against type of field
+// Slices support "append" and "unique" and maps support "deep", besides the default "replace" strategy
+func (in *Input) isSupportedMerge(t reflect.Type, tags *ConfigTags) error {
+	for t.Kind() == reflect.Ptr && !in.isDecodable(t) {
+		t = t.Elem()
+	}
+
+	switch tags.Merge {
+	case "", mergeReplace:
+		return nil
+
+	case mergeAppend, mergeUnique:
+		if t.Kind() == reflect.Slice && !in.isDecodable(t) && !in.isStructList(t) {
+			return nil
+		}
+
+	case mergeDeep:
+		if isMap(t) && !in.isDecodable(t) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(unsupportedMergeErrFormat, ErrUnsupportedMerge, tags.Merge, t)
+}
+
+// isNested reports whether type is a struct which its fields should be traversed
+func (in *Input) isNested(t reflect.Type) bool {
+	return isStruct(t) && !in.isDecodable(t)
+}
+
+// isDecodable reports whether a custom decoder is registered for type
+// or it implements encoding.TextUnmarshaler or json.Unmarshaler
+func (in *Input) isDecodable(t reflect.Type) bool {
+	if _, exists := in.decoders[t]; exists {
+		return true
+	}
+
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(jsonUnmarshalerType)
 }
 
 func (in *Input) isSupportedType(t reflect.Type) error {
+	if in.isDecodable(t) {
+		return nil
+	}
+
 	switch t.Kind() {
 	case reflect.Invalid,
 		reflect.Uintptr,
@@ -135,12 +289,23 @@ func (in *Input) isSupportedType(t reflect.Type) error {
 	case reflect.Slice, reflect.Array:
 		switch t.Elem().Kind() {
 		case reflect.Slice, reflect.Array:
-			return fmt.Errorf(unsupportedTypeErrFormat, ErrUnsupportedType, "multi-dimensional slice/array")
+			if !in.isDecodable(t.Elem()) {
+				return fmt.Errorf(unsupportedTypeErrFormat, ErrUnsupportedType, "multi-dimensional slice/array")
+			}
+
+			return nil
 
 		default:
 			return in.isSupportedType(t.Elem())
 		}
 
+	case reflect.Map:
+		if err := in.isSupportedType(t.Key()); err != nil {
+			return err
+		}
+
+		return in.isSupportedType(t.Elem())
+
 	case reflect.Ptr:
 		return in.isSupportedType(t.Elem())
 	}
@@ -165,6 +330,21 @@ func (in *Input) SetValue(f *Field, value string) error {
 		value = os.ExpandEnv(value)
 	}
 
+	if decode, exists := in.decoders[f.Value.Type()]; exists {
+		return in.setDecoded(f, decode, value)
+	}
+
+	// time.Time is parsed using format tag instead of its UnmarshalText method
+	if !isTime(f.Value.Type()) && f.Value.CanAddr() {
+		switch u := f.Value.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			return in.setText(f, u, value)
+
+		case json.Unmarshaler:
+			return in.setJSON(f, u, value)
+		}
+	}
+
 	switch f.Value.Kind() {
 	case reflect.String:
 		return in.setString(f, value)
@@ -223,7 +403,7 @@ func (in *Input) setBool(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 
@@ -236,13 +416,13 @@ func (in *Input) setInt(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 	if f.Value.OverflowInt(i) {
 		return fmt.Errorf(
 			overflowErrFormat,
-			ErrValueOverflow, i, f.Value.Kind(), in.getPath(f.Path),
+			ErrValueOverflow, redact(f, i), f.Value.Kind(), in.getPath(f.Path),
 		)
 	}
 
@@ -255,13 +435,13 @@ func (in *Input) setUint(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 	if f.Value.OverflowUint(i) {
 		return fmt.Errorf(
 			overflowErrFormat,
-			ErrValueOverflow, i, f.Value.Kind(), in.getPath(f.Path),
+			ErrValueOverflow, redact(f, i), f.Value.Kind(), in.getPath(f.Path),
 		)
 	}
 
@@ -274,13 +454,13 @@ func (in *Input) setFloat(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 	if f.Value.OverflowFloat(fv) {
 		return fmt.Errorf(
 			overflowErrFormat,
-			ErrValueOverflow, fv, f.Value.Kind(), in.getPath(f.Path),
+			ErrValueOverflow, redact(f, fv), f.Value.Kind(), in.getPath(f.Path),
 		)
 	}
 
@@ -293,13 +473,13 @@ func (in *Input) setComplex(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 	if f.Value.OverflowComplex(c) {
 		return fmt.Errorf(
 			overflowErrFormat,
-			ErrValueOverflow, c, f.Value.Kind(), in.getPath(f.Path),
+			ErrValueOverflow, redact(f, c), f.Value.Kind(), in.getPath(f.Path),
 		)
 	}
 
@@ -327,10 +507,48 @@ func (in *Input) setSlice(f *Field, value string) error {
 		}
 	}
 
-	f.Value.Set(s)
+	f.Value.Set(in.mergeSlice(f, s))
 	return nil
 }
 
+// mergeSlice merges items with the current value of a slice field based on merge tag
+func (in *Input) mergeSlice(f *Field, items reflect.Value) reflect.Value {
+	if f.Value.Kind() != reflect.Slice || f.Value.Len() == 0 {
+		return items
+	}
+
+	switch f.Tags.Merge {
+	case mergeAppend:
+		merged := reflect.MakeSlice(f.Value.Type(), 0, f.Value.Len()+items.Len())
+		return reflect.AppendSlice(reflect.AppendSlice(merged, f.Value), items)
+
+	case mergeUnique:
+		merged := reflect.MakeSlice(f.Value.Type(), 0, f.Value.Len()+items.Len())
+		for _, list := range []reflect.Value{f.Value, items} {
+			for i := 0; i < list.Len(); i++ {
+				if !containsItem(merged, list.Index(i)) {
+					merged = reflect.Append(merged, list.Index(i))
+				}
+			}
+		}
+
+		return merged
+	}
+
+	return items
+}
+
+// containsItem reports whether list contains an item deeply equal to v
+func containsItem(list, v reflect.Value) bool {
+	for i := 0; i < list.Len(); i++ {
+		if reflect.DeepEqual(list.Index(i).Interface(), v.Interface()) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (in *Input) setArray(f *Field, value string) error {
 	items := extractItems(value, f.Tags.Separator)
 	size := f.Value.Len()
@@ -357,10 +575,171 @@ func (in *Input) setArray(f *Field, value string) error {
 }
 
 func (in *Input) setMap(f *Field, value string) error {
-	// TODO
+	items := extractItems(value, f.Tags.Separator)
+	if len(items) == 0 {
+		return nil
+	}
+
+	entries := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		kv := strings.SplitN(item, f.Tags.KVSeparator, 2)
+		if len(kv) != 2 {
+			return fmt.Errorf(
+				parseErrFormat,
+				ErrParsing, in.getPath(f.Path), redact(f, fmt.Sprintf("invalid map entry %q", item)),
+			)
+		}
+
+		entries[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return in.setMapValues(f, entries)
+}
+
+// setMapValues sets the value of a map field from the given entries
+// Entries are either strings or values decoded from files, e.g. a JSON object
+func (in *Input) setMapValues(f *Field, entries map[string]interface{}) error {
+	if f.Value.Kind() == reflect.Ptr {
+		if f.Value.IsNil() {
+			initPtr(f.Value)
+		}
+
+		pointedField := Field{
+			Value: f.Value.Elem(),
+			Tags:  f.Tags,
+			Path:  f.Path,
+		}
+
+		return in.setMapValues(&pointedField, entries)
+	}
+
+	t := f.Value.Type()
+	m := reflect.MakeMapWithSize(t, len(entries))
+
+	// Deep merge starts from a copy of the current entries, so maps provided by the user are not modified
+	deep := f.Tags.Merge == mergeDeep && !f.Value.IsNil()
+	if deep {
+		iter := f.Value.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+
+	for k, v := range entries {
+		keyField := Field{
+			Value: reflect.New(t.Key()).Elem(),
+			Tags:  f.Tags,
+			Path:  f.Path,
+		}
+		if err := in.SetValue(&keyField, k); err != nil {
+			return err
+		}
+
+		elemField := Field{
+			Value: reflect.New(t.Elem()).Elem(),
+			Tags:  f.Tags,
+			Path:  f.Path,
+		}
+		if current := m.MapIndex(keyField.Value); deep && current.IsValid() {
+			elemField.Value.Set(current)
+		}
+
+		if err := in.setContent(&elemField, v); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(keyField.Value, elemField.Value)
+	}
+
+	f.Value.Set(m)
+	return nil
+}
+
+// setContent sets the value of a field from a value decoded from file content
+// Lists and maps are set item by item, other values are converted to string and set by SetValue
+func (in *Input) setContent(f *Field, value interface{}) error {
+	if list, ok := toList(value); ok && in.isList(f.Value.Type()) {
+		return in.setList(f, list)
+	}
+
+	if entries, ok := toStringMap(value); ok && isMap(f.Value.Type()) && !in.isDecodable(f.Value.Type()) {
+		return in.setMapValues(f, entries)
+	}
+
+	return in.SetValue(f, in.formatContent(f, value))
+}
+
+// setList sets the value of a slice or array field from a decoded list, item by item
+func (in *Input) setList(f *Field, list []interface{}) error {
+	if f.Value.Kind() == reflect.Ptr {
+		if f.Value.IsNil() {
+			initPtr(f.Value)
+		}
+
+		pointedField := Field{
+			Value: f.Value.Elem(),
+			Tags:  f.Tags,
+			Path:  f.Path,
+		}
+
+		return in.setList(&pointedField, list)
+	}
+
+	t := f.Value.Type()
+	var v reflect.Value
+	if t.Kind() == reflect.Array {
+		if len(list) > t.Len() {
+			return fmt.Errorf(overflowErrFormat, ErrValueOverflow, len(list), t, in.getPath(f.Path))
+		}
+
+		v = reflect.New(t).Elem()
+	} else {
+		v = reflect.MakeSlice(t, len(list), len(list))
+	}
+
+	for i, item := range list {
+		itemField := Field{
+			Value: v.Index(i),
+			Tags:  f.Tags,
+			Path:  f.Path,
+		}
+
+		if err := in.setContent(&itemField, item); err != nil {
+			return err
+		}
+	}
+
+	f.Value.Set(in.mergeSlice(f, v))
 	return nil
 }
 
+// formatContent converts a decoded value into string
+// Times are formatted by format tag, objects and lists are encoded as JSON for decodable types
+func (in *Input) formatContent(f *Field, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(f.Tags.Format)
+
+	case map[string]interface{}, []interface{}:
+		if in.isDecodable(f.Value.Type()) {
+			if b, err := json.Marshal(v); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	return stringify(value, f.Tags.Separator)
+}
+
+// isList reports whether type is a slice or array, or a pointer to one, which is not decodable
+func (in *Input) isList(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr && !in.isDecodable(t) {
+		t = t.Elem()
+	}
+
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !in.isDecodable(t)
+}
+
 func (in *Input) setPointer(f *Field, value string) error {
 	if f.Value.IsNil() {
 		initPtr(f.Value)
@@ -380,7 +759,7 @@ func (in *Input) setDuration(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 
@@ -393,7 +772,7 @@ func (in *Input) setTime(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 
@@ -406,7 +785,7 @@ func (in *Input) setUrl(f *Field, value string) error {
 	if err != nil {
 		return fmt.Errorf(
 			parseErrFormat,
-			ErrParsing, in.getPath(f.Path), err,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
 		)
 	}
 
@@ -414,11 +793,72 @@ func (in *Input) setUrl(f *Field, value string) error {
 	return nil
 }
 
+func (in *Input) setDecoded(f *Field, decode DecodeFunc, value string) error {
+	d, err := decode(value)
+	if err != nil {
+		return fmt.Errorf(
+			parseErrFormat,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
+		)
+	}
+
+	v := reflect.ValueOf(d)
+	if !v.IsValid() {
+		f.Value.Set(reflect.Zero(f.Value.Type()))
+		return nil
+	}
+	if !v.Type().AssignableTo(f.Value.Type()) {
+		if !v.Type().ConvertibleTo(f.Value.Type()) {
+			return fmt.Errorf(
+				parseErrFormat,
+				ErrParsing, in.getPath(f.Path), fmt.Sprintf("decoder returned %v instead of %v", v.Type(), f.Value.Type()),
+			)
+		}
+
+		v = v.Convert(f.Value.Type())
+	}
+
+	f.Value.Set(v)
+	return nil
+}
+
+func (in *Input) setText(f *Field, u encoding.TextUnmarshaler, value string) error {
+	if err := u.UnmarshalText([]byte(value)); err != nil {
+		return fmt.Errorf(
+			parseErrFormat,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
+		)
+	}
+
+	return nil
+}
+
+func (in *Input) setJSON(f *Field, u json.Unmarshaler, value string) error {
+	data := []byte(value)
+	if !json.Valid(data) {
+		// Plain strings are quoted to make a valid JSON
+		data, _ = json.Marshal(value)
+	}
+
+	if err := u.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf(
+			parseErrFormat,
+			ErrParsing, in.getPath(f.Path), redact(f, err),
+		)
+	}
+
+	return nil
+}
+
 func initPtr(v reflect.Value) {
 	v.Set(reflect.New(v.Type().Elem()))
 }
 
 // getPath returns a dot separated string prefixed with struct name
 func (in *Input) getPath(paths []string) string {
+	if len(paths) == 0 {
+		return in.Name
+	}
+
 	return in.Name + "." + strings.Join(paths, ".")
 }
@@ -1,11 +1,15 @@
 package gonfig
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"net"
 	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -59,6 +63,11 @@ type badTypes struct {
 	Aip  *[0]interface{}
 	Au   [0]unsafe.Pointer
 	Aup  *[0]unsafe.Pointer
+	Mc   map[string]chan int
+	Mcp  *map[string]chan int
+	Mkc  map[chan int]string
+	Mf   map[string]func()
+	Mi   map[string]interface{}
 }
 
 type supportedTypes struct {
@@ -217,13 +226,13 @@ type supportedTypes struct {
 	DurationSlice    []time.Duration
 	DurationPtrSlice []*time.Duration
 
-	// Map         map[string]string
-	// MapPtr      *map[string]string
-	// MapArray    [2]map[string]string
-	// MapArrayPtr *[2]map[string]string
-	// MapPtrArray [2]*map[string]string
-	// MapSlice    []map[string]string
-	// MapPtrSlice []*map[string]string
+	Map         map[string]string
+	MapPtr      *map[string]string
+	MapArray    [2]map[string]string
+	MapArrayPtr *[2]map[string]string
+	MapPtrArray [2]*map[string]string
+	MapSlice    []map[string]string
+	MapPtrSlice []*map[string]string
 
 	Time         time.Time
 	TimePtr      *time.Time
@@ -257,6 +266,41 @@ type supportedTypes struct {
 	*EmbedP
 }
 
+type logLevel int
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	for i, name := range []string{"debug", "info", "warn"} {
+		if name == string(text) {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown log level %q", text)
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p *point) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+type jsonVersion int
+
+func (v *jsonVersion) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		data = []byte(s)
+	}
+
+	i, err := strconv.Atoi(string(data))
+	*v = jsonVersion(i)
+	return err
+}
+
 type Embed struct {
 	Int    int
 	String string
@@ -373,7 +417,7 @@ func TestNewInput(t *testing.T) {
 
 		tags := struct {
 			Defaults int
-			Keys     int `config:"TAGS" json:"tags,omitempty" yaml:"tags" toml:""`
+			Keys     int `config:"TAGS" prefix:"KEYS_" json:"tags,omitempty" yaml:"tags" toml:""`
 			Others   int `default:"5" required:"true" expand:"true" separator:"," format:"good-format"`
 			Ignored1 int `config:"-"`
 			Ignored2 int `ignore:"true"`
@@ -391,6 +435,7 @@ func TestNewInput(t *testing.T) {
 
 		keys := in.Fields[1]
 		ass.Equal("TAGS", keys.Tags.Config)
+		ass.Equal("KEYS_", keys.Tags.Prefix)
 		ass.Equal("tags", keys.Tags.Json)
 		ass.Equal("tags", keys.Tags.Yaml)
 		ass.Equal("", keys.Tags.Toml)
@@ -403,6 +448,39 @@ func TestNewInput(t *testing.T) {
 		ass.Equal("good-format", others.Tags.Format)
 	})
 
+	t.Run("unsupported merge", func(t *testing.T) {
+		t.Parallel()
+
+		for _, input := range []interface{}{
+			&struct {
+				Hosts []string `merge:"deep"`
+			}{},
+			&struct {
+				Labels map[string]string `merge:"append"`
+			}{},
+			&struct {
+				Host string `merge:"unique"`
+			}{},
+			&struct {
+				Ports [2]int `merge:"append"`
+			}{},
+			&struct {
+				Servers []struct{ Host string } `merge:"append"`
+			}{},
+			&struct {
+				Hosts []string `merge:"prepend"`
+			}{},
+		} {
+			_, err := NewInput(input)
+			require.Error(t, err)
+			assert.Truef(
+				t,
+				errors.Is(err, ErrUnsupportedMerge),
+				"Error must wrap ErrUnsupportedMerge error",
+			)
+		}
+	})
+
 	t.Run("path", func(t *testing.T) {
 		t.Parallel()
 
@@ -414,6 +492,10 @@ func TestNewInput(t *testing.T) {
 				Second struct {
 					Third        int
 					ThirdSibling int
+					Deep         struct {
+						Fourth        int
+						FourthSibling int
+					}
 				}
 
 				SecondSibling int
@@ -423,11 +505,13 @@ func TestNewInput(t *testing.T) {
 		in, err := NewInput(&paths)
 		req.NoError(err)
 		req.NotNil(in)
-		req.Len(in.Fields, 3)
+		req.Len(in.Fields, 5)
 
 		ass.Equal([]string{"First", "Second", "Third"}, in.Fields[0].Path)
 		ass.Equal([]string{"First", "Second", "ThirdSibling"}, in.Fields[1].Path)
-		ass.Equal([]string{"First", "SecondSibling"}, in.Fields[2].Path)
+		ass.Equal([]string{"First", "Second", "Deep", "Fourth"}, in.Fields[2].Path)
+		ass.Equal([]string{"First", "Second", "Deep", "FourthSibling"}, in.Fields[3].Path)
+		ass.Equal([]string{"First", "SecondSibling"}, in.Fields[4].Path)
 	})
 
 	t.Run("supported types", func(t *testing.T) {
@@ -436,7 +520,7 @@ func TestNewInput(t *testing.T) {
 		in, err := NewInput(new(supportedTypes))
 		require.NoError(t, err)
 		require.NotNil(t, in)
-		require.Len(t, in.Fields, 163)
+		require.Len(t, in.Fields, 170)
 	})
 }
 
@@ -507,6 +591,7 @@ func TestInput_SetValue(t *testing.T) {
 			r    rune          = 1
 			s    string        = "nice"
 			d    time.Duration = 60000000000
+			m                  = map[string]string{"nice": "config"}
 		)
 		ti, _ := time.Parse(time.RFC3339, "2020-11-26T18:26:14+03:30")
 		ti2, _ := time.Parse(time.RFC3339, "2021-11-26T18:26:49+03:30")
@@ -669,6 +754,14 @@ func TestInput_SetValue(t *testing.T) {
 			{"1m 1s", []time.Duration{60000000000, 1000000000}},
 			{"1m 1m", []*time.Duration{&d, &d}},
 
+			{"nice:config", map[string]string{"nice": "config"}},
+			{"nice:config", &m},
+			{"nice:config nice:config", [2]map[string]string{m, m}},
+			{"nice:config nice:config", &[2]map[string]string{m, m}},
+			{"nice:config nice:config", [2]*map[string]string{&m, &m}},
+			{"nice:config nice:config", []map[string]string{m, m}},
+			{"nice:config", []*map[string]string{&m}},
+
 			{"2020-11-26T18:26:14+03:30", ti},
 			{"2020-11-26T18:26:14+03:30", &ti},
 			{"2020-11-26T18:26:14+03:30 2021-11-26T18:26:49+03:30", [2]time.Time{ti, ti2}},
@@ -766,6 +859,203 @@ func TestInput_SetValue(t *testing.T) {
 		}
 	})
 
+	t.Run("maps", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := url.Parse("golang.org")
+		input := struct {
+			Labels    map[string]string `separator:","`
+			Timeouts  map[string]time.Duration
+			Ports     map[int]*uint16 `kvseparator:"="`
+			Endpoints map[string]url.URL
+		}{}
+
+		in, err := NewInput(&input)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+		require.Len(t, in.Fields, 4)
+
+		require.NoError(t, in.SetValue(in.Fields[0], "team:core, env:prod"))
+		assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, input.Labels)
+
+		require.NoError(t, in.SetValue(in.Fields[1], "read:1s write:1m"))
+		assert.Equal(t, map[string]time.Duration{"read": time.Second, "write": time.Minute}, input.Timeouts)
+
+		require.NoError(t, in.SetValue(in.Fields[2], "80=8080"))
+		require.Contains(t, input.Ports, 80)
+		assert.Equal(t, uint16(8080), *input.Ports[80])
+
+		require.NoError(t, in.SetValue(in.Fields[3], "go:golang.org"))
+		assert.Equal(t, map[string]url.URL{"go": *u}, input.Endpoints)
+
+		for _, tc := range []struct {
+			field int
+			value string
+		}{
+			{0, "team"},
+			{1, "read:forever"},
+			{2, "http=80"},
+		} {
+			err := in.SetValue(in.Fields[tc.field], tc.value)
+			require.Error(t, err)
+			assert.Truef(
+				t,
+				errors.Is(err, ErrParsing),
+				"Error must wrap ErrParsing error",
+			)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		t.Parallel()
+
+		input := struct {
+			Origins  []string `separator:"," merge:"append"`
+			Tags     []string `merge:"unique"`
+			Hosts    []string `merge:"replace"`
+			Labels   map[string]string
+			Limits   map[string]map[string]int `merge:"deep"`
+			Timeouts *map[string]time.Duration `merge:"deep"`
+		}{
+			Labels: map[string]string{"team": "core"},
+		}
+		base := map[string]map[string]int{"api": {"rps": 10, "burst": 20}}
+		input.Limits = base
+
+		in, err := NewInput(&input)
+		require.NoError(t, err)
+		require.Len(t, in.Fields, 6)
+
+		require.NoError(t, in.SetValue(in.Fields[0], "a.com, b.com"))
+		require.NoError(t, in.SetValue(in.Fields[0], "c.com"))
+		assert.Equal(t, []string{"a.com", "b.com", "c.com"}, input.Origins)
+
+		require.NoError(t, in.SetValue(in.Fields[1], "x y"))
+		require.NoError(t, in.setContent(in.Fields[1], []interface{}{"y", "z", "z"}))
+		assert.Equal(t, []string{"x", "y", "z"}, input.Tags)
+
+		require.NoError(t, in.SetValue(in.Fields[2], "a b"))
+		require.NoError(t, in.SetValue(in.Fields[2], "c"))
+		assert.Equal(t, []string{"c"}, input.Hosts)
+
+		require.NoError(t, in.SetValue(in.Fields[3], "env:prod"))
+		assert.Equal(t, map[string]string{"env": "prod"}, input.Labels)
+
+		require.NoError(t, in.setContent(in.Fields[4], map[string]interface{}{
+			"api": map[string]interface{}{"rps": "50"},
+			"web": map[string]interface{}{"rps": "5"},
+		}))
+		assert.Equal(t, map[string]map[string]int{"api": {"rps": 50, "burst": 20}, "web": {"rps": 5}}, input.Limits)
+		assert.Equal(t, map[string]map[string]int{"api": {"rps": 10, "burst": 20}}, base)
+
+		require.NoError(t, in.SetValue(in.Fields[5], "read:1s"))
+		require.NoError(t, in.SetValue(in.Fields[5], "write:1m"))
+		assert.Equal(t, map[string]time.Duration{"read": time.Second, "write": time.Minute}, *input.Timeouts)
+	})
+
+	t.Run("unmarshalers", func(t *testing.T) {
+		t.Parallel()
+
+		input := struct {
+			Level    logLevel
+			LevelPtr *logLevel
+			Levels   []logLevel
+			IP       net.IP
+			IPs      []net.IP
+			Point    point
+			Version  jsonVersion
+			Versions map[string]jsonVersion
+		}{}
+
+		in, err := NewInput(&input)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+		require.Len(t, in.Fields, 8)
+
+		values := []string{
+			"warn",
+			"debug",
+			"debug warn",
+			"127.0.0.1",
+			"127.0.0.1 ::1",
+			"1,2",
+			"2",
+			`v1:1 v2:"2"`,
+		}
+		for i := range values {
+			require.NoError(t, in.SetValue(in.Fields[i], values[i]))
+		}
+
+		assert.Equal(t, logLevel(2), input.Level)
+		require.NotNil(t, input.LevelPtr)
+		assert.Equal(t, logLevel(0), *input.LevelPtr)
+		assert.Equal(t, []logLevel{0, 2}, input.Levels)
+		assert.Equal(t, "127.0.0.1", input.IP.String())
+		require.Len(t, input.IPs, 2)
+		assert.Equal(t, "::1", input.IPs[1].String())
+		assert.Equal(t, point{1, 2}, input.Point)
+		assert.Equal(t, jsonVersion(2), input.Version)
+		assert.Equal(t, map[string]jsonVersion{"v1": 1, "v2": 2}, input.Versions)
+
+		for i, value := range []string{"trace", "", "debug trace", "localhost"} {
+			err := in.SetValue(in.Fields[i], value)
+			require.Error(t, err)
+			assert.Truef(
+				t,
+				errors.Is(err, ErrParsing),
+				"Error must wrap ErrParsing error",
+			)
+		}
+	})
+
+	t.Run("decoders", func(t *testing.T) {
+		t.Parallel()
+
+		input := struct {
+			Pattern *regexp.Regexp
+			Any     interface{}
+			Level   logLevel
+			Short   int8
+		}{}
+
+		decoders := map[reflect.Type]DecodeFunc{
+			reflect.TypeOf(&regexp.Regexp{}): func(value string) (interface{}, error) {
+				return regexp.Compile(value)
+			},
+			reflect.TypeOf(&input.Any).Elem(): func(value string) (interface{}, error) {
+				return strings.ToUpper(value), nil
+			},
+			reflect.TypeOf(logLevel(0)): func(value string) (interface{}, error) {
+				return len(value), nil
+			},
+			reflect.TypeOf(int8(0)): func(value string) (interface{}, error) {
+				return value, nil
+			},
+		}
+
+		in, err := newInput(&input, decoders)
+		require.NoError(t, err)
+		require.NotNil(t, in)
+		require.Len(t, in.Fields, 4)
+
+		require.NoError(t, in.SetValue(in.Fields[0], "^go"))
+		assert.True(t, input.Pattern.MatchString("gonfig"))
+
+		require.NoError(t, in.SetValue(in.Fields[1], "any"))
+		assert.Equal(t, "ANY", input.Any)
+
+		require.NoError(t, in.SetValue(in.Fields[2], "trace"))
+		assert.Equal(t, logLevel(5), input.Level)
+
+		err = in.SetValue(in.Fields[3], "1")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrParsing))
+
+		err = in.SetValue(in.Fields[0], "(")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrParsing))
+	})
+
 	t.Run("value overflow", func(t *testing.T) {
 		t.Parallel()
 
@@ -1,5 +1,7 @@
 package gonfig
 
+import "context"
+
 // Provider is used to provide values
 // It can implement either Unmarshaler or Filler interface or both
 // Name method is used for error messages
@@ -16,3 +18,16 @@ type Unmarshaler interface {
 type Filler interface {
 	Fill(in *Input) (err error)
 }
+
+// Watcher can be implemented by providers to notify about changes of their source
+// It is used by Config.Watch to reload values
+type Watcher interface {
+	// Watch blocks until ctx is done and sends on changed whenever the source is modified
+	Watch(ctx context.Context, changed chan<- struct{}) (err error)
+}
+
+// Validator can be implemented by root and nested structs to validate their values
+// Validate is called after all providers are applied and fields are validated
+type Validator interface {
+	Validate() (err error)
+}
@@ -0,0 +1,106 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// remoteTimeout limits requests of remote providers when loading values
+	remoteTimeout = 10 * time.Second
+
+	// maxResponseSize limits size of responses read by remote providers
+	maxResponseSize int64 = 32 << 20
+)
+
+// fillKV fills fields from flat key values of a key value store
+// Keys are split by sep into nested maps and looked up the same way as file content
+// Values are marked as found at source followed by their key, e.g. app/Redis/Hosts
+func fillKV(in *Input, pairs map[string]string, source, sep, provider string, strict bool) error {
+	fp := FileProvider{
+		FilePath:     source,
+		keySeparator: sep,
+	}
+
+	content := kvContent(pairs, sep)
+	if err := fp.fill(in, in.Fields, content, provider); err != nil {
+		return err
+	}
+
+	if strict || in.strict {
+		return fp.unknownKeys(in, content)
+	}
+
+	return nil
+}
+
+// kvContent converts flat keys separated by sep into nested maps, e.g. {"redis": {"hosts": value}} for redis/hosts
+// A key which is also the parent of other keys is discarded
+func kvContent(pairs map[string]string, sep string) map[string]interface{} {
+	content := make(map[string]interface{})
+	for _, key := range sortedKeys(pairs) {
+		trimmed := strings.Trim(key, sep)
+		if trimmed == "" {
+			continue
+		}
+
+		names := strings.Split(trimmed, sep)
+		m := content
+		for _, name := range names[:len(names)-1] {
+			nested, ok := m[name].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				m[name] = nested
+			}
+
+			m = nested
+		}
+
+		last := names[len(names)-1]
+		if _, isParent := m[last].(map[string]interface{}); !isParent {
+			m[last] = pairs[key]
+		}
+	}
+
+	return content
+}
+
+// errNotModified is returned by fetch for responses of conditional requests when content is not modified
+var errNotModified = errors.New("not modified")
+
+// fetch sends req and returns body of a successful response, client defaults to http.DefaultClient
+// Not found responses are reported by ErrKeyNotFound and other unsuccessful ones by ErrRequestFailed
+func fetch(client *http.Client, req *http.Request) ([]byte, http.Header, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, resp.Header, errNotModified
+
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, resp.Header, fmt.Errorf(requestErrFormat, ErrKeyNotFound, req.Method, req.URL.Redacted(), resp.Status)
+
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, resp.Header, fmt.Errorf(requestErrFormat, ErrRequestFailed, req.Method, req.URL.Redacted(), resp.Status)
+	}
+
+	return body, resp.Header, nil
+}
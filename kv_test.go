@@ -0,0 +1,45 @@
+package gonfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVContent(t *testing.T) {
+	content := kvContent(map[string]string{
+		"host":           "golang.org",
+		"/redis/hosts/":  "a.com b.com",
+		"redis":          "discarded",
+		"servers/0/host": "s0.com",
+		"servers/1/host": "s1.com",
+		"":               "empty",
+	}, "/")
+
+	assert.Equal(t, map[string]interface{}{
+		"host": "golang.org",
+		"redis": map[string]interface{}{
+			"hosts": "a.com b.com",
+		},
+		"servers": map[string]interface{}{
+			"0": map[string]interface{}{"host": "s0.com"},
+			"1": map[string]interface{}{"host": "s1.com"},
+		},
+	}, content)
+}
+
+func TestToList(t *testing.T) {
+	list, ok := toList(map[string]interface{}{"1": "b", "0": "a"})
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"a", "b"}, list)
+
+	for _, m := range []map[string]interface{}{
+		{},
+		{"0": "a", "2": "c"},
+		{"00": "a"},
+		{"a": "a"},
+	} {
+		_, ok := toList(m)
+		assert.False(t, ok, m)
+	}
+}
@@ -0,0 +1,86 @@
+package gonfig
+
+import "reflect"
+
+// Option configures a Config, used by New
+type Option func(c *Config)
+
+// WithEnv adds an EnvProvider to Providers list
+func WithEnv() Option {
+	return func(c *Config) { c.FromEnv() }
+}
+
+// WithFlags adds a FlagProvider to Providers list, command line arguments are taken from os.Args
+func WithFlags() Option {
+	return func(c *Config) { c.FromFlags() }
+}
+
+// WithFile adds a FileProvider to Providers list, or an EnvProvider in case of .env file
+func WithFile(path string) Option {
+	return func(c *Config) { c.FromFile(path) }
+}
+
+// WithGlob adds a GlobProvider to Providers list
+func WithGlob(pattern string) Option {
+	return func(c *Config) { c.FromGlob(pattern) }
+}
+
+// WithFileSearch adds a FileSearchProvider to Providers list, which loads the first file found
+func WithFileSearch(name string, dirs ...string) Option {
+	return func(c *Config) { c.FromFileSearch(name, dirs...) }
+}
+
+// WithDirectory adds a DirectoryProvider to Providers list
+func WithDirectory(dir string) Option {
+	return func(c *Config) { c.FromDirectory(dir) }
+}
+
+// WithConsul adds a ConsulProvider to Providers list for keys under prefix
+func WithConsul(prefix string) Option {
+	return func(c *Config) { c.FromConsul(prefix) }
+}
+
+// WithEtcd adds an EtcdProvider to Providers list for keys under prefix
+func WithEtcd(prefix string) Option {
+	return func(c *Config) { c.FromEtcd(prefix) }
+}
+
+// WithVault adds a VaultProvider to Providers list for secret at path, along with secrets referenced by vault tags
+func WithVault(path string) Option {
+	return func(c *Config) { c.FromVault(path) }
+}
+
+// WithSSM adds an SSMProvider to Providers list for parameters under path of AWS SSM Parameter Store
+func WithSSM(path string) Option {
+	return func(c *Config) { c.FromSSM(path) }
+}
+
+// WithHTTP adds an HTTPProvider to Providers list for content served at url
+func WithHTTP(url string) Option {
+	return func(c *Config) { c.FromHTTP(url) }
+}
+
+// WithProvider adds a Provider to Providers list
+func WithProvider(p Provider) Option {
+	return func(c *Config) { c.AddProvider(p) }
+}
+
+// WithProfile activates profiles, applied in the specified order
+func WithProfile(names ...string) Option {
+	return func(c *Config) { c.Profile(names...) }
+}
+
+// WithProfileFromEnv activates comma separated profiles listed in env var key
+func WithProfileFromEnv(key string) Option {
+	return func(c *Config) { c.ProfileFromEnv(key) }
+}
+
+// WithStrict enables strict mode for all providers supporting it
+func WithStrict() Option {
+	return func(c *Config) { c.Strict() }
+}
+
+// WithDecoder registers a custom decoder for fields of type t
+func WithDecoder(t reflect.Type, fn DecodeFunc) Option {
+	return func(c *Config) { c.RegisterDecoder(t, fn) }
+}
@@ -0,0 +1,53 @@
+package gonfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	c := Load()
+	for _, opt := range []Option{
+		WithEnv(),
+		WithFlags(),
+		WithFile("config.yaml"),
+		WithFile(".env"),
+		WithGlob("conf.d/*"),
+		WithFileSearch("app"),
+		WithDirectory("/run/secrets"),
+		WithConsul("app"),
+		WithEtcd("/app/"),
+		WithVault("secret/data/app"),
+		WithSSM("/app/prod"),
+		WithHTTP("https://config.internal/app.json"),
+		WithProvider(new(FileProvider)),
+		WithProfile("prod"),
+		WithProfileFromEnv("APP_PROFILE"),
+		WithStrict(),
+		WithDecoder(reflect.TypeOf(point{}), func(string) (interface{}, error) { return point{}, nil }),
+	} {
+		opt(c)
+	}
+
+	require.Len(t, c.Providers, 13)
+	assert.IsType(t, new(EnvProvider), c.Providers[0])
+	assert.IsType(t, new(FlagProvider), c.Providers[1])
+	assert.IsType(t, new(FileProvider), c.Providers[2])
+	assert.IsType(t, new(EnvProvider), c.Providers[3])
+	assert.IsType(t, new(GlobProvider), c.Providers[4])
+	assert.IsType(t, new(FileSearchProvider), c.Providers[5])
+	assert.IsType(t, new(DirectoryProvider), c.Providers[6])
+	assert.IsType(t, new(ConsulProvider), c.Providers[7])
+	assert.IsType(t, new(EtcdProvider), c.Providers[8])
+	assert.IsType(t, new(VaultProvider), c.Providers[9])
+	assert.IsType(t, new(SSMProvider), c.Providers[10])
+	assert.IsType(t, new(HTTPProvider), c.Providers[11])
+	assert.IsType(t, new(FileProvider), c.Providers[12])
+	assert.Equal(t, []string{"prod"}, c.Profiles())
+	assert.Equal(t, "APP_PROFILE", c.profileEnv)
+	assert.True(t, c.strict)
+	assert.Contains(t, c.decoders, reflect.TypeOf(point{}))
+}
@@ -0,0 +1,268 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// plans caches compiled plans by planKey, safe for concurrent use
+	plans sync.Map
+
+	// typeIDs assigns a unique id to each type with a registered decoder, used for building planKey
+	typeIDs    sync.Map
+	lastTypeID uint64
+)
+
+// planKey identifies a plan by struct type and types of registered decoders
+// Decoders change which types are traversed, so the same struct type may have different plans
+type planKey struct {
+	t        reflect.Type
+	decoders string
+}
+
+// plan is the compiled result of traversing a struct type, reused by inputs of the same type
+// Tags and paths are shared between inputs, so they must not be modified
+// Keys of providers e.g. env var names and file paths depend on settings of each provider,
+// so they are not cached and are built from tags and paths on each load
+type plan struct {
+	// fields to be collected, in order of declaration
+	fields []planField
+
+	// nested structs along with the root struct, ordered from the deepest
+	structs []planField
+}
+
+// planField describes how to reach a field from the root struct, along with its information
+type planField struct {
+	steps    []planStep
+	tags     *ConfigTags
+	path     []string
+	levels   []pathLevel
+	embedded bool
+	prefixes []structPrefix
+}
+
+// planStep is the index of a struct field, followed by number of pointers to dereference
+// Index of the root step is -1, as it refers to the root value itself
+type planStep struct {
+	index  int
+	derefs int
+}
+
+// traverse collects fields and nested structs of a struct field using the cached plan of its type
+func (in *Input) traverse(f *Field) error {
+	p, err := in.plan(f.Value.Type(), f.Path)
+	if err != nil {
+		return err
+	}
+
+	in.apply(p, f)
+	return nil
+}
+
+// plan returns the cached plan of type t, compiling it on first use
+// path is only used for error messages, plans are relative to the root struct
+func (in *Input) plan(t reflect.Type, path []string) (*plan, error) {
+	key := planKey{t: t, decoders: in.decodersKey()}
+	if p, exists := plans.Load(key); exists {
+		return p.(*plan), nil
+	}
+
+	p, err := in.compile(t, path)
+	if err != nil {
+		return nil, err
+	}
+
+	plans.Store(key, p)
+	return p, nil
+}
+
+// compile traverses type t and returns its plan
+func (in *Input) compile(t reflect.Type, path []string) (*plan, error) {
+	var p plan
+	root := planField{
+		steps: []planStep{{index: -1}},
+		tags:  new(ConfigTags),
+	}
+
+	if err := in.compileField(&p, root, t, path); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// compileField recursively traverses all fields and records their information
+func (in *Input) compileField(p *plan, f planField, t reflect.Type, base []string) error {
+	if f.tags.Ignore {
+		return nil
+	}
+
+	if err := in.isSupportedType(t); err != nil {
+		return fmt.Errorf(badFieldErrFormat, in.getPath(appendPaths(base, f.path)), err)
+	}
+
+	if err := in.isSupportedMerge(t, f.tags); err != nil {
+		return fmt.Errorf(badFieldErrFormat, in.getPath(appendPaths(base, f.path)), err)
+	}
+
+	if in.isNested(t) {
+		prefixes := f.prefixes
+		if f.tags.Prefix != "" {
+			prefixes = appendPrefix(prefixes, structPrefix{depth: len(f.path), value: f.tags.Prefix})
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+
+			// Unexported fields are not settable
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			tags := extractTags(sf.Tag)
+			nestedField := planField{
+				steps:    appendStep(f.steps, planStep{index: i}),
+				tags:     tags,
+				path:     appendPath(f.path, sf.Name),
+				levels:   appendLevel(f.levels, pathLevel{tags: tags, embedded: sf.Anonymous}),
+				embedded: sf.Anonymous,
+				prefixes: prefixes,
+			}
+
+			if err := in.compileField(p, nestedField, sf.Type, base); err != nil {
+				return err
+			}
+		}
+
+		p.structs = append(p.structs, f)
+		return nil
+	}
+
+	if t.Kind() == reflect.Ptr && !in.isDecodable(t) && in.isNested(t.Elem()) {
+		last := f.steps[len(f.steps)-1]
+		last.derefs++
+		f.steps = append(f.steps[:len(f.steps)-1:len(f.steps)-1], last)
+
+		return in.compileField(p, f, t.Elem(), base)
+	}
+
+	p.fields = append(p.fields, f)
+	return nil
+}
+
+// apply collects fields and nested structs of f by its plan
+// Nil pointers to nested structs are initialized along the way
+func (in *Input) apply(p *plan, f *Field) {
+	for _, s := range p.structs {
+		v := resolve(f.Value, s.steps)
+
+		// Methods of embedded structs are promoted to the parent
+		if !s.embedded {
+			in.structs = append(in.structs, in.planned(f, s, v))
+		}
+	}
+
+	for _, pf := range p.fields {
+		in.collectField(in.planned(f, pf, resolve(f.Value, pf.steps)))
+	}
+}
+
+// planned returns a Field of value v described by pf, relative to root field f
+func (in *Input) planned(f *Field, pf planField, v reflect.Value) *Field {
+	field := Field{
+		Value:    v,
+		Tags:     pf.tags,
+		Path:     pf.path,
+		levels:   pf.levels,
+		embedded: pf.embedded,
+		prefixes: pf.prefixes,
+	}
+
+	if len(pf.steps) == 1 {
+		field.Tags = f.Tags
+		field.embedded = f.embedded
+	}
+
+	if len(f.Path) != 0 {
+		field.Path = appendPaths(f.Path, pf.path)
+		field.prefixes = nil
+		for _, prefix := range pf.prefixes {
+			field.prefixes = append(field.prefixes, structPrefix{depth: prefix.depth + len(f.Path), value: prefix.value})
+		}
+	}
+
+	return &field
+}
+
+// resolve returns the value reached by steps from root
+func resolve(root reflect.Value, steps []planStep) reflect.Value {
+	v := root
+	for _, s := range steps {
+		if s.index >= 0 {
+			v = v.Field(s.index)
+		}
+
+		for i := 0; i < s.derefs; i++ {
+			if v.IsNil() {
+				initPtr(v)
+			}
+
+			v = v.Elem()
+		}
+	}
+
+	return v
+}
+
+// decodersKey returns a key identifying types of registered decoders
+func (in *Input) decodersKey() string {
+	if len(in.decoders) == 0 {
+		return ""
+	}
+
+	ids := make([]int, 0, len(in.decoders))
+	for t := range in.decoders {
+		ids = append(ids, typeID(t))
+	}
+	sort.Ints(ids)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = strconv.Itoa(id)
+	}
+
+	return strings.Join(keys, ",")
+}
+
+// typeID returns the unique id of type t
+func typeID(t reflect.Type) int {
+	if id, exists := typeIDs.Load(t); exists {
+		return id.(int)
+	}
+
+	id, _ := typeIDs.LoadOrStore(t, int(atomic.AddUint64(&lastTypeID, 1)))
+	return id.(int)
+}
+
+// appendStep returns a new slice with step appended, leaving the original slice untouched
+func appendStep(steps []planStep, step planStep) []planStep {
+	newSteps := make([]planStep, len(steps), len(steps)+1)
+	copy(newSteps, steps)
+
+	return append(newSteps, step)
+}
+
+// appendLevel returns a new slice with level appended, leaving the original slice untouched
+func appendLevel(levels []pathLevel, level pathLevel) []pathLevel {
+	newLevels := make([]pathLevel, len(levels), len(levels)+1)
+	copy(newLevels, levels)
+
+	return append(newLevels, level)
+}
@@ -0,0 +1,165 @@
+package gonfig
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type coordinates struct {
+	Lat, Lng float64
+}
+
+type planConfig struct {
+	Host     string        `required:"true"`
+	Timeout  time.Duration `default:"5s"`
+	Location coordinates
+	Cache    *struct {
+		Hosts []string `separator:","`
+		TTL   time.Duration
+	} `prefix:"REDIS_"`
+	Servers []struct {
+		Host string
+		Port int `default:"8080"`
+	}
+	ignored string
+}
+
+func TestInput_plan(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		var s1, s2 planConfig
+		in1, err := NewInput(&s1)
+		require.NoError(t, err)
+		in2, err := NewInput(&s2)
+		require.NoError(t, err)
+
+		require.Len(t, in1.Fields, 7)
+		require.Len(t, in2.Fields, 7)
+		for i := range in1.Fields {
+			assert.Same(t, in1.Fields[i].Tags, in2.Fields[i].Tags)
+			assert.Equal(t, in1.Fields[i].Path, in2.Fields[i].Path)
+		}
+
+		// Values are resolved from each input and nil pointers are initialized
+		require.NotNil(t, s1.Cache)
+		require.NotNil(t, s2.Cache)
+		require.NoError(t, in1.SetValue(in1.Fields[0], "a.com"))
+		assert.Equal(t, "a.com", s1.Host)
+		assert.Equal(t, "", s2.Host)
+
+		_, exists := plans.Load(planKey{t: reflect.TypeOf(s1)})
+		assert.True(t, exists)
+	})
+
+	t.Run("decoders", func(t *testing.T) {
+		decoders := map[reflect.Type]DecodeFunc{
+			reflect.TypeOf(coordinates{}): func(string) (interface{}, error) { return coordinates{}, nil },
+		}
+
+		var s planConfig
+		in, err := newInput(&s, decoders)
+		require.NoError(t, err)
+		require.Len(t, in.Fields, 6)
+		assert.Equal(t, []string{"Location"}, in.Fields[2].Path)
+
+		in, err = newInput(&s, nil)
+		require.NoError(t, err)
+		require.Len(t, in.Fields, 7)
+		assert.Equal(t, []string{"Location", "Lat"}, in.Fields[2].Path)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		var s planConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		servers := in.Fields[6]
+		require.NoError(t, in.setItems(servers, 2))
+		require.Len(t, servers.items, 2)
+		assert.Equal(t, []string{"Servers", "1", "Port"}, servers.items[1].Fields[1].Path)
+		assert.Equal(t, []string{"Servers", "0", "Port"}, servers.items[0].Fields[1].Path)
+		assert.Same(t, servers.items[0].Fields[1].Tags, servers.items[1].Fields[1].Tags)
+
+		require.NoError(t, in.SetValue(servers.items[1].Fields[1], "90"))
+		assert.Equal(t, 90, s.Servers[1].Port)
+		assert.Equal(t, 0, s.Servers[0].Port)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		type bad struct {
+			Ch chan int
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := NewInput(&bad{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Ch")
+		}
+
+		_, exists := plans.Load(planKey{t: reflect.TypeOf(bad{})})
+		assert.False(t, exists)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		type concurrent struct {
+			A, B string
+			C    struct{ D *int }
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var s concurrent
+				in, err := NewInput(&s)
+				assert.NoError(t, err)
+				assert.Len(t, in.Fields, 3)
+			}()
+		}
+
+		wg.Wait()
+	})
+}
+
+func BenchmarkNewInput(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var s planConfig
+			if _, err := NewInput(&s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Compiles the plan on each call, which is done once per type when cached
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var s planConfig
+			in := Input{Name: "planConfig"}
+			f := Field{Value: reflect.ValueOf(&s).Elem(), Tags: new(ConfigTags)}
+
+			p, err := in.compile(f.Value.Type(), nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			in.apply(p, &f)
+		}
+	})
+}
+
+func BenchmarkConfig_Into(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var s planConfig
+		_ = Load().Into(&s)
+	}
+}
@@ -0,0 +1,188 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// searchExtensions are supported file extensions, in order of precedence within a directory
+var searchExtensions = []string{JSON, YAML, YML, TOML, ENV}
+
+// FileSearchProvider loads values from files named FileName with any supported extension found in search path
+type FileSearchProvider struct {
+	// FileName is the name of file without extension
+	FileName string
+
+	// Dirs are searched in order of precedence
+	// Defaults to ".", $XDG_CONFIG_HOME/<FileName> (or $HOME/.config/<FileName>), $HOME/.<FileName> and /etc/<FileName>
+	Dirs []string
+
+	// Whether to load all files found instead of the first one, defaults to false
+	// Files found earlier in the search path take precedence
+	All bool
+
+	// Whether to report error if no file is found, defaults to false
+	Required bool
+
+	// Whether to report keys in files which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Files found during the last call to Fill
+	mu   sync.Mutex
+	used []string
+}
+
+var (
+	_ Provider = (*FileSearchProvider)(nil)
+	_ Filler   = (*FileSearchProvider)(nil)
+	_ Watcher  = (*FileSearchProvider)(nil)
+)
+
+// NewFileSearchProvider creates a new FileSearchProvider for name, searching dirs or default search path
+func NewFileSearchProvider(name string, dirs ...string) *FileSearchProvider {
+	return &FileSearchProvider{
+		FileName: name,
+		Dirs:     dirs,
+		All:      false,
+		Required: false,
+		Strict:   false,
+	}
+}
+
+// Name of provider
+func (sp *FileSearchProvider) Name() string {
+	return "File search provider"
+}
+
+// Fill takes struct fields and fills their values from files found
+// Files are applied from the lowest precedence, so values of files found earlier override others
+// Overlays of found files are loaded for profiles of Config loading in
+func (sp *FileSearchProvider) Fill(in *Input) error {
+	paths := sp.sources(in.profiles)
+	sp.mu.Lock()
+	sp.used = paths
+	sp.mu.Unlock()
+
+	if len(paths) == 0 {
+		if sp.Required {
+			return fmt.Errorf(fileNotFoundErrFormat, os.ErrNotExist, sp.FileName, strings.Join(sp.dirs(), ", "))
+		}
+
+		return nil
+	}
+
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := sp.provider(paths[i]).Fill(in); err != nil {
+			return prefixErrors(paths[i], err)
+		}
+	}
+
+	return nil
+}
+
+// UsedFiles returns files found during the last call to Fill, in order of precedence
+func (sp *FileSearchProvider) UsedFiles() []string {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	return sp.used
+}
+
+// Watch sends on changed whenever a file inside one of the search directories is modified
+func (sp *FileSearchProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	dirs := sp.dirs()
+	errs := make(chan error, len(dirs))
+	for _, dir := range dirs {
+		go func(dir string) {
+			errs <- watchDirectory(ctx, dir, changed)
+		}(dir)
+	}
+
+	for range dirs {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sources returns files found along with their existing overlays of profiles, in order of precedence
+func (sp *FileSearchProvider) sources(profiles []string) []string {
+	var paths []string
+	for _, file := range sp.find() {
+		for i := len(profiles) - 1; i >= 0; i-- {
+			if overlay := overlayPath(file, profiles[i]); fileState(overlay).exists {
+				paths = append(paths, overlay)
+			}
+		}
+
+		paths = append(paths, file)
+	}
+
+	return paths
+}
+
+// find returns files found in search path in order of precedence, only the first one unless All is set
+func (sp *FileSearchProvider) find() []string {
+	var files []string
+	for _, dir := range sp.dirs() {
+		for _, ext := range searchExtensions {
+			path := filepath.Join(dir, sp.FileName+ext)
+			if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+
+			if !sp.All {
+				return []string{path}
+			}
+
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+// dirs returns Dirs, or default search path if not specified
+func (sp *FileSearchProvider) dirs() []string {
+	if len(sp.Dirs) != 0 {
+		return sp.Dirs
+	}
+
+	dirs := []string{"."}
+	home, _ := os.UserHomeDir()
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, sp.FileName))
+	} else if home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config", sp.FileName))
+	}
+
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, "."+sp.FileName))
+	}
+
+	return append(dirs, filepath.Join("/etc", sp.FileName))
+}
+
+// provider returns a required provider for file found in search path
+func (sp *FileSearchProvider) provider(path string) Filler {
+	switch p := newFileProvider(path).(type) {
+	case *EnvProvider:
+		p.Required = true
+		p.Strict = sp.Strict
+		return p
+
+	case *FileProvider:
+		p.Required = true
+		p.Strict = sp.Strict
+		return p
+	}
+
+	return nil
+}
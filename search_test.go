@@ -0,0 +1,145 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileSearchProvider(t *testing.T) {
+	sp := NewFileSearchProvider("app", "/first", "/second")
+	require.NotNil(t, sp)
+	assert.Equal(t, "app", sp.FileName)
+	assert.Equal(t, []string{"/first", "/second"}, sp.Dirs)
+	assert.Equal(t, false, sp.All)
+	assert.Equal(t, false, sp.Required)
+	assert.Equal(t, false, sp.Strict)
+}
+
+func TestFileSearchProvider_Name(t *testing.T) {
+	sp := NewFileSearchProvider("app")
+	assert.Equal(t, "File search provider", sp.Name())
+}
+
+func TestFileSearchProvider_Fill(t *testing.T) {
+	first, second, third := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFiles(t, first, map[string]string{"app.json": `{"host": "first"}`})
+	writeFiles(t, second, map[string]string{
+		"app.toml":      "host = \"second\"\nport = 2\n",
+		"app.yaml":      "port: 3\ndebug: true\n",
+		"app.prod.toml": "port = 4\n",
+	})
+	writeFiles(t, third, map[string]string{"app.env": "HOST=third\nTIMEOUT=5s\n"})
+
+	type searchConfig struct {
+		Host    string
+		Port    int
+		Debug   bool
+		Timeout string
+	}
+
+	t.Run("first match", func(t *testing.T) {
+		os.Clearenv()
+		var s searchConfig
+		c := Load().FromFileSearch("app", filepath.Join(first, "missing"), second, first)
+		err := c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, searchConfig{Port: 3, Debug: true}, s)
+		assert.Equal(t, []string{filepath.Join(second, "app.yaml")}, c.UsedFiles())
+	})
+
+	t.Run("merge all", func(t *testing.T) {
+		os.Clearenv()
+		var s searchConfig
+		c := Load().FromFileSearchAll("app", first, second, third).Profile("prod")
+		err := c.Into(&s)
+		require.NoError(t, err)
+		assert.Equal(t, searchConfig{"first", 3, true, "5s"}, s)
+		assert.Equal(t, []string{
+			filepath.Join(first, "app.json"),
+			filepath.Join(second, "app.yaml"),
+			filepath.Join(second, "app.prod.toml"),
+			filepath.Join(second, "app.toml"),
+			filepath.Join(third, "app.env"),
+		}, c.UsedFiles())
+	})
+
+	t.Run("shared provider", func(t *testing.T) {
+		os.Clearenv()
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"app.yaml":      "port: 3\n",
+			"app.prod.yaml": "port: 4\n",
+		})
+
+		sp := NewFileSearchProvider("app", dir)
+		prod := Load().AddProvider(sp).Profile("prod")
+		dev := Load().AddProvider(sp)
+
+		// Profiles of one Config do not leak into another sharing the provider
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				var s searchConfig
+				assert.NoError(t, prod.Into(&s))
+				assert.Equal(t, 4, s.Port)
+			}()
+			go func() {
+				defer wg.Done()
+				var s searchConfig
+				assert.NoError(t, dev.Into(&s))
+				assert.Equal(t, 3, s.Port)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("required", func(t *testing.T) {
+		in, err := NewInput(&searchConfig{})
+		require.NoError(t, err)
+
+		sp := NewFileSearchProvider("missing", first)
+		err = sp.Fill(in)
+		assert.NoError(t, err)
+
+		sp.Required = true
+		err = sp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"app.json": `{"host": "a", "hots": "b"}`})
+
+		in, err := NewInput(&searchConfig{})
+		require.NoError(t, err)
+
+		sp := NewFileSearchProvider("app", dir)
+		sp.Strict = true
+		err = sp.Fill(in)
+		require.Error(t, err)
+		ce, ok := err.(ConfigErrors)
+		require.True(t, ok)
+		require.Len(t, ce, 1)
+		assert.EqualError(t, ce[0], filepath.Join(dir, "app.json")+`: unknown key "hots", did you mean "Host"?`)
+	})
+
+	t.Run("default dirs", func(t *testing.T) {
+		os.Clearenv()
+		require.NoError(t, os.Setenv("HOME", "/home/gopher"))
+
+		sp := NewFileSearchProvider("app")
+		assert.Equal(t, []string{".", "/home/gopher/.config/app", "/home/gopher/.app", "/etc/app"}, sp.dirs())
+
+		require.NoError(t, os.Setenv("XDG_CONFIG_HOME", "/xdg"))
+		assert.Equal(t, []string{".", "/xdg/app", "/home/gopher/.app", "/etc/app"}, sp.dirs())
+	})
+}
@@ -0,0 +1,58 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// secretMask replaces secret values in errors, usage text, explain report and string representations
+const secretMask = "******"
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Secret is a string which is masked when printed or marshaled to JSON
+// Use string conversion to access the actual value e.g. string(c.Password)
+type Secret string
+
+// String returns the mask instead of actual value
+func (s Secret) String() string {
+	return secretMask
+}
+
+// GoString returns the mask instead of actual value, used by %#v verb
+func (s Secret) GoString() string {
+	return secretMask
+}
+
+// MarshalJSON returns the mask as a JSON string instead of actual value
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(secretMask)
+}
+
+// isSecret reports whether field is tagged as secret, references a Vault secret, is provided by a secret store
+// or its type contains Secret
+func isSecret(f *Field) bool {
+	if f.secret || f.Tags != nil && (f.Tags.Secret || f.Tags.Vault != "") {
+		return true
+	}
+
+	t := f.Value.Type()
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		}
+
+		return t == secretType
+	}
+}
+
+// redact returns the mask instead of value for secret fields
+func redact(f *Field, value interface{}) interface{} {
+	if isSecret(f) {
+		return secretMask
+	}
+
+	return value
+}
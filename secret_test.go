@@ -0,0 +1,97 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	s := Secret("password")
+
+	assert.Equal(t, "password", string(s))
+	assert.Equal(t, secretMask, s.String())
+	assert.Equal(t, secretMask, fmt.Sprint(s))
+	assert.Equal(t, secretMask, fmt.Sprintf("%#v", s))
+
+	b, err := json.Marshal(struct{ Password Secret }{s})
+	require.NoError(t, err)
+	assert.Equal(t, `{"Password":"******"}`, string(b))
+}
+
+func TestInput_SetValue_secret(t *testing.T) {
+	input := struct {
+		Pin      int    `secret:"true"`
+		Small    int8   `secret:"true"`
+		Pins     []uint `secret:"true"`
+		Password Secret
+		Keys     map[string]Secret
+	}{}
+
+	in, err := NewInput(&input)
+	require.NoError(t, err)
+	require.Len(t, in.Fields, 5)
+
+	tests := []struct {
+		value  string
+		target error
+	}{
+		{"p4ssw0rd", ErrParsing},
+		{"1234", ErrValueOverflow},
+		{"12 p4ssw0rd", ErrParsing},
+		{"p4ssw0rd", nil},
+		{"p4ssw0rd", ErrParsing},
+	}
+
+	for i, tc := range tests {
+		err := in.SetValue(in.Fields[i], tc.value)
+		if tc.target == nil {
+			require.NoError(t, err)
+			continue
+		}
+
+		require.Error(t, err)
+		assert.Truef(t, errors.Is(err, tc.target), "Error must wrap %v error", tc.target)
+		assert.NotContains(t, err.Error(), "p4ssw0rd")
+		assert.NotContains(t, err.Error(), "1234")
+		assert.Contains(t, err.Error(), secretMask)
+	}
+	assert.Equal(t, Secret("p4ssw0rd"), input.Password)
+}
+
+type secretConfig struct {
+	Password Secret
+	Token    string `secret:"true" default:"t0k3n"`
+}
+
+func TestConfig_secret(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("PASSWORD", "p4ssw0rd")
+	require.NoError(t, err)
+
+	var s secretConfig
+
+	c := Load().FromEnv()
+	err = c.Into(&s)
+	require.NoError(t, err)
+	assert.Equal(t, Secret("p4ssw0rd"), s.Password)
+	assert.Equal(t, "t0k3n", s.Token)
+
+	explain := c.Explain()
+	assert.NotContains(t, explain, "p4ssw0rd")
+	assert.NotContains(t, explain, "t0k3n")
+	assert.Equal(t, 2, strings.Count(explain, secretMask))
+
+	var b bytes.Buffer
+	err = c.Usage(&b, &s)
+	require.NoError(t, err)
+	assert.NotContains(t, b.String(), "t0k3n")
+	assert.Contains(t, b.String(), secretMask)
+}
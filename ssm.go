@@ -0,0 +1,274 @@
+package gonfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ssmTarget     = "AmazonSSM.GetParametersByPath"
+	ssmMaxResults = 10
+
+	awsRegionEnv           = "AWS_REGION"
+	awsDefaultRegionEnv    = "AWS_DEFAULT_REGION"
+	awsAccessKeyIDEnv      = "AWS_ACCESS_KEY_ID"
+	awsSecretAccessKeyEnv  = "AWS_SECRET_ACCESS_KEY"
+	awsSessionTokenEnv     = "AWS_SESSION_TOKEN"
+	ssmEndpointEnv         = "AWS_ENDPOINT_URL_SSM"
+	containerCredsURIEnv   = "AWS_CONTAINER_CREDENTIALS_FULL_URI"
+	containerCredsPathEnv  = "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"
+	containerCredsTokenEnv = "AWS_CONTAINER_AUTHORIZATION_TOKEN"
+	containerCredsHost     = "http://169.254.170.2"
+)
+
+// SSMProvider loads values from AWS Systems Manager Parameter Store to provided struct
+// Parameters under Path are mapped onto field paths, e.g. /app/prod/db/host for DB.Host with "/app/prod" path
+type SSMProvider struct {
+	// Path of parameters hierarchy, e.g. "/app/prod"
+	Path string
+
+	// Region of AWS, defaults to $AWS_REGION or $AWS_DEFAULT_REGION
+	Region string
+
+	// Endpoint of SSM API, defaults to $AWS_ENDPOINT_URL_SSM or https://ssm.<region>.amazonaws.com
+	Endpoint string
+
+	// Credentials used for signing requests, default to $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN
+	// If not found, credentials of ECS task role are used
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Whether to report error if no parameter is found under Path, defaults to false
+	Required bool
+
+	// Whether to report parameters which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Client is used for sending requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	// Credentials of ECS task role, reused until they expire
+	mu          sync.Mutex
+	creds       awsCredentials
+	credsExpiry time.Time
+}
+
+var (
+	_ Provider = (*SSMProvider)(nil)
+	_ Filler   = (*SSMProvider)(nil)
+)
+
+type ssmResponse struct {
+	Parameters []struct {
+		Name  string
+		Value string
+	}
+	NextToken string
+}
+
+// NewSSMProvider creates a new SSMProvider for parameters under path
+func NewSSMProvider(path string) *SSMProvider {
+	return &SSMProvider{
+		Path:     path,
+		Required: false,
+		Strict:   false,
+	}
+}
+
+// Name of provider
+func (sp *SSMProvider) Name() string {
+	return "SSM provider"
+}
+
+// Fill takes struct fields and fills their values from parameters under Path
+// SecureString parameters are decrypted and StringList values are separated by ","
+func (sp *SSMProvider) Fill(in *Input) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	pairs, err := sp.parameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(pairs) == 0 {
+		if sp.Required {
+			return fmt.Errorf(remoteKeyNotFoundErrFormat, ErrKeyNotFound, sp.path())
+		}
+
+		return nil
+	}
+
+	return fillKV(in, pairs, "ssm:"+strings.TrimSuffix(sp.path(), "/")+"/", "/", sp.Name(), sp.Strict)
+}
+
+// parameters returns values of all parameters under Path relative to it, following pages of results
+func (sp *SSMProvider) parameters(ctx context.Context) (map[string]string, error) {
+	region := sp.region()
+	endpoint, err := sp.endpoint(region)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := sp.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path := sp.path()
+	pairs := make(map[string]string)
+	var token string
+	for {
+		body := map[string]interface{}{
+			"Path":           path,
+			"Recursive":      true,
+			"WithDecryption": true,
+			"MaxResults":     ssmMaxResults,
+		}
+		if token != "" {
+			body["NextToken"] = token
+		}
+
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", ssmTarget)
+		signV4(req, b, creds, region, "ssm", time.Now())
+
+		respBody, _, err := fetch(sp.Client, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp ssmResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return nil, fmt.Errorf(decodeFailedErrFormat, err)
+		}
+
+		for _, p := range resp.Parameters {
+			pairs[strings.TrimPrefix(p.Name, path)] = p.Value
+		}
+
+		if resp.NextToken == "" {
+			return pairs, nil
+		}
+		token = resp.NextToken
+	}
+}
+
+// credentials returns credentials for signing requests
+// Credentials of ECS task role are fetched if not specified and not found in env
+// ErrNoCredentials is returned if none of them is available
+func (sp *SSMProvider) credentials(ctx context.Context) (awsCredentials, error) {
+	creds := awsCredentials{
+		AccessKeyID:     sp.AccessKeyID,
+		SecretAccessKey: sp.SecretAccessKey,
+		SessionToken:    sp.SessionToken,
+	}
+	if creds.AccessKeyID == "" {
+		creds = awsCredentials{
+			AccessKeyID:     os.Getenv(awsAccessKeyIDEnv),
+			SecretAccessKey: os.Getenv(awsSecretAccessKeyEnv),
+			SessionToken:    os.Getenv(awsSessionTokenEnv),
+		}
+	}
+	if creds.AccessKeyID != "" {
+		return creds, nil
+	}
+
+	uri := os.Getenv(containerCredsURIEnv)
+	if path := os.Getenv(containerCredsPathEnv); uri == "" && path != "" {
+		uri = containerCredsHost + path
+	}
+	if uri == "" {
+		return creds, fmt.Errorf(noAWSCredentialsErrFormat, ErrNoCredentials, awsAccessKeyIDEnv, awsSecretAccessKeyEnv)
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.creds.AccessKeyID != "" && time.Now().Before(sp.credsExpiry) {
+		return sp.creds, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return creds, err
+	}
+	if token := os.Getenv(containerCredsTokenEnv); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	body, _, err := fetch(sp.Client, req)
+	if err != nil {
+		return creds, err
+	}
+
+	var resp struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return creds, fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	// Credentials are refreshed a few minutes before expiry, so they do not expire during requests
+	sp.creds = awsCredentials{AccessKeyID: resp.AccessKeyID, SecretAccessKey: resp.SecretAccessKey, SessionToken: resp.Token}
+	sp.credsExpiry = resp.Expiration.Add(-5 * time.Minute)
+
+	return sp.creds, nil
+}
+
+// path returns Path starting with "/"
+func (sp *SSMProvider) path() string {
+	if strings.HasPrefix(sp.Path, "/") {
+		return sp.Path
+	}
+
+	return "/" + sp.Path
+}
+
+// region returns Region, falling back to $AWS_REGION and $AWS_DEFAULT_REGION
+func (sp *SSMProvider) region() string {
+	if sp.Region != "" {
+		return sp.Region
+	}
+	if region := os.Getenv(awsRegionEnv); region != "" {
+		return region
+	}
+
+	return os.Getenv(awsDefaultRegionEnv)
+}
+
+// endpoint returns Endpoint, falling back to $AWS_ENDPOINT_URL_SSM and the regional endpoint
+// ErrNoRegion is returned if none of them is available
+func (sp *SSMProvider) endpoint(region string) (string, error) {
+	if sp.Endpoint != "" {
+		return sp.Endpoint, nil
+	}
+	if endpoint := os.Getenv(ssmEndpointEnv); endpoint != "" {
+		return endpoint, nil
+	}
+	if region == "" {
+		return "", fmt.Errorf(noAWSRegionErrFormat, ErrNoRegion, awsRegionEnv, awsDefaultRegionEnv)
+	}
+
+	return "https://ssm." + region + ".amazonaws.com/", nil
+}
@@ -0,0 +1,264 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSM emulates GetParametersByPath action of SSM API, verifying signatures of requests
+// It also serves credentials of ECS task role at /creds
+type fakeSSM struct {
+	t      *testing.T
+	params map[string]string
+	creds  awsCredentials
+	pages  int
+}
+
+func (fs *fakeSSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/creds" {
+		if r.Header.Get("Authorization") != "ecs-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"AccessKeyId":     fs.creds.AccessKeyID,
+			"SecretAccessKey": fs.creds.SecretAccessKey,
+			"Token":           fs.creds.SessionToken,
+			"Expiration":      time.Now().Add(time.Hour),
+		})
+		return
+	}
+
+	var body struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		MaxResults     int
+		NextToken      string
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	require.NoError(fs.t, err)
+	require.NoError(fs.t, json.Unmarshal(b, &body))
+
+	if !fs.verify(r, b) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"InvalidSignatureException"}`))
+		return
+	}
+
+	assert.Equal(fs.t, ssmTarget, r.Header.Get("X-Amz-Target"))
+	assert.True(fs.t, body.Recursive)
+	assert.True(fs.t, body.WithDecryption)
+
+	var names []string
+	for name := range fs.params {
+		if strings.HasPrefix(name, strings.TrimSuffix(body.Path, "/")+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start, _ := strconv.Atoi(body.NextToken)
+	end := start + body.MaxResults
+	resp := map[string]interface{}{}
+	if end < len(names) {
+		resp["NextToken"] = strconv.Itoa(end)
+	} else {
+		end = len(names)
+	}
+
+	var params []map[string]string
+	for _, name := range names[start:end] {
+		params = append(params, map[string]string{"Name": name, "Value": fs.params[name], "Type": "String"})
+	}
+	resp["Parameters"] = params
+	fs.pages++
+
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// verify signs the received request again and compares signatures
+func (fs *fakeSSM) verify(r *http.Request, body []byte) bool {
+	now, err := time.Parse(sigV4TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	req, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	req.Header.Set("X-Amz-Target", r.Header.Get("X-Amz-Target"))
+	signV4(req, body, fs.creds, "eu-west-1", "ssm", now)
+
+	return req.Header.Get("Authorization") == r.Header.Get("Authorization")
+}
+
+type ssmConfig struct {
+	DB struct {
+		Host     string
+		Password string
+	}
+	Hosts []string `separator:","`
+	Extra struct {
+		Keys map[string]string
+	}
+}
+
+func TestNewSSMProvider(t *testing.T) {
+	sp := NewSSMProvider("/app/prod")
+	require.NotNil(t, sp)
+	assert.Equal(t, "/app/prod", sp.Path)
+	assert.Equal(t, "", sp.Region)
+	assert.Equal(t, "", sp.Endpoint)
+	assert.Equal(t, false, sp.Required)
+	assert.Equal(t, false, sp.Strict)
+}
+
+func TestSSMProvider_Name(t *testing.T) {
+	sp := NewSSMProvider("/app/prod")
+	assert.Equal(t, "SSM provider", sp.Name())
+}
+
+func TestSSMProvider_Fill(t *testing.T) {
+	fs := &fakeSSM{
+		t: t,
+		params: map[string]string{
+			"/app/prod/db/host":     "db.internal",
+			"/app/prod/db/password": "p4ss",
+			"/app/prod/hosts":       "a.com,b.com",
+			"/app/dev/db/host":      "localhost",
+		},
+		creds: awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "SESSION"},
+	}
+	for i := 0; i < 12; i++ {
+		fs.params[fmt.Sprintf("/app/prod/extra/keys/k%02d", i)] = strconv.Itoa(i)
+	}
+	server := httptest.NewServer(fs)
+	defer server.Close()
+
+	t.Run("env credentials", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+		for k, v := range map[string]string{
+			awsRegionEnv:          "eu-west-1",
+			ssmEndpointEnv:        server.URL,
+			awsAccessKeyIDEnv:     "AKID",
+			awsSecretAccessKeyEnv: "SECRET",
+			awsSessionTokenEnv:    "SESSION",
+		} {
+			require.NoError(t, os.Setenv(k, v))
+		}
+
+		var s ssmConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		fs.pages = 0
+		sp := NewSSMProvider("/app/prod")
+		sp.Strict = true
+		err = sp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", s.DB.Host)
+		assert.Equal(t, "p4ss", s.DB.Password)
+		assert.Equal(t, []string{"a.com", "b.com"}, s.Hosts)
+		assert.Len(t, s.Extra.Keys, 12)
+		assert.Equal(t, "11", s.Extra.Keys["k11"])
+		assert.Equal(t, 2, fs.pages)
+		assert.Equal(t, "ssm:/app/prod/DB/Host", in.Fields[0].Key)
+	})
+
+	t.Run("container credentials", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+		require.NoError(t, os.Setenv(containerCredsURIEnv, server.URL+"/creds"))
+		require.NoError(t, os.Setenv(containerCredsTokenEnv, "ecs-token"))
+
+		var s ssmConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		sp := NewSSMProvider("app/dev/")
+		sp.Region = "eu-west-1"
+		sp.Endpoint = server.URL
+		err = sp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", s.DB.Host)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		os.Clearenv()
+
+		in, err := NewInput(&ssmConfig{})
+		require.NoError(t, err)
+
+		sp := NewSSMProvider("/app/prod")
+		sp.Region = "eu-west-1"
+		sp.Endpoint = server.URL
+		err = sp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrNoCredentials))
+		assert.Contains(t, err.Error(), awsAccessKeyIDEnv)
+	})
+
+	t.Run("no region", func(t *testing.T) {
+		os.Clearenv()
+
+		in, err := NewInput(&ssmConfig{})
+		require.NoError(t, err)
+
+		sp := NewSSMProvider("/app/prod")
+		sp.AccessKeyID = "AKID"
+		sp.SecretAccessKey = "SECRET"
+		err = sp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrNoRegion))
+		assert.Contains(t, err.Error(), awsRegionEnv)
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		in, err := NewInput(&ssmConfig{})
+		require.NoError(t, err)
+
+		sp := NewSSMProvider("/app/prod")
+		sp.Region = "eu-west-1"
+		sp.Endpoint = server.URL
+		sp.AccessKeyID = "AKID"
+		sp.SecretAccessKey = "WRONG"
+		err = sp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequestFailed))
+		assert.NotContains(t, err.Error(), "WRONG")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		in, err := NewInput(&ssmConfig{})
+		require.NoError(t, err)
+
+		sp := NewSSMProvider("/missing")
+		sp.Region = "eu-west-1"
+		sp.Endpoint = server.URL
+		sp.AccessKeyID = "AKID"
+		sp.SecretAccessKey = "SECRET"
+		sp.SessionToken = "SESSION"
+		err = sp.Fill(in)
+		require.NoError(t, err)
+
+		sp.Required = true
+		err = sp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+}
@@ -7,9 +7,25 @@ import (
 )
 
 const (
-	ignoreCharacter  = "-"
-	defaultSeparator = " "
-	defaultFormat    = time.RFC3339
+	ignoreCharacter    = "-"
+	defaultSeparator   = " "
+	defaultKVSeparator = ":"
+	defaultFormat      = time.RFC3339
+)
+
+// Merge strategies for values of a field provided by multiple providers
+const (
+	// mergeReplace replaces the previous value, it is the default for all types
+	mergeReplace = "replace"
+
+	// mergeAppend appends slice items to the previous ones
+	mergeAppend = "append"
+
+	// mergeUnique appends slice items to the previous ones, skipping duplicates
+	mergeUnique = "unique"
+
+	// mergeDeep merges map entries into the previous ones, recursively for nested maps
+	mergeDeep = "deep"
 )
 
 // ConfigTags indicates possible tags
@@ -19,6 +35,10 @@ type ConfigTags struct {
 	// Use "-" to ignore the field.
 	Config string
 
+	// Prefix of env keys of a nested struct fields, replacing its own name e.g. `prefix:"CACHE_"`.
+	// Config tags of fields inside the struct are relative to the prefix.
+	Prefix string
+
 	// json tag for json files
 	Json string
 
@@ -28,46 +48,125 @@ type ConfigTags struct {
 	// toml tag for toml files
 	Toml string
 
+	// flag tag for command line flags, defaults to field path in kebab-case.
+	// Use "-" to skip defining a flag for the field.
+	Flag string
+
+	// Shorthand name for command line flag, specified after flag name e.g. `flag:"hosts,H"`.
+	Shorthand string
+
 	// Default value for field.
 	Default string
 
+	// Description of field used in usage text, "usage" tag can be used alternatively.
+	Description string
+
 	// Specify if value should be present, defaults to false.
 	Required bool
 
+	// Minimum value for numbers and durations, or minimum length for strings, slices, arrays and maps.
+	Min string
+
+	// Maximum value for numbers and durations, or maximum length for strings, slices, arrays and maps.
+	Max string
+
+	// Exact length for strings, slices, arrays and maps.
+	Len string
+
+	// Space separated list of allowed values, applied to each item of slices and arrays.
+	OneOf string
+
+	// Regular expression which string values should match, applied to each item of slices and arrays.
+	Regex string
+
+	// Specify if value should not be the zero value of its type, defaults to false.
+	NonZero bool
+
+	// Space separated pairs of sibling field path and value, field is required if all of them match
+	// e.g. `required_if:"TLS.Enabled true"`.
+	RequiredIf string
+
+	// Space separated list of sibling field paths, field is required if any of them has a value.
+	RequiredWith string
+
+	// Space separated list of sibling field paths, field must be empty if any of them has a value.
+	ExcludedWith string
+
+	// Specify if value should be redacted in errors, usage text and explain report, defaults to false.
+	Secret bool
+
+	// Reference to a key of Vault secret used by VaultProvider, e.g. `vault:"secret/data/db#password"`.
+	// Values of referenced secrets are redacted the same as secret fields.
+	Vault string
+
 	// Specify if field should be ignored, defaults to false.
 	Ignore bool
 
 	// Specify if value should be expanded from env, defaults to false.
 	Expand bool
 
-	// Separator to be used for slice/array items, defaults to " ".
+	// Separator to be used for slice/array items and map entries, defaults to " ".
 	Separator string
 
+	// Separator to be used between key and value of map entries, defaults to ":".
+	KVSeparator string
+
 	// Format to be used for parsing time strings, defaults to time.RFC3339.
 	Format string
+
+	// Strategy for merging with the value set by previous providers, defaults to "replace".
+	// Slices support "append" and "unique", maps support "deep".
+	Merge string
+
+	// tag is the raw struct tag, used to look up profile specific tags e.g. `default.prod:"..."`
+	tag reflect.StructTag
 }
 
 // Returns default config tags.
 func extractTags(st reflect.StructTag) *ConfigTags {
 	tags := ConfigTags{
-		Config:    st.Get("config"),
-		Json:      extractKeyName(st.Get("json")),
-		Yaml:      extractKeyName(st.Get("yaml")),
-		Toml:      extractKeyName(st.Get("toml")),
-		Default:   st.Get("default"),
-		Required:  st.Get("required") == "true",
-		Ignore:    st.Get("ignore") == "true",
-		Expand:    st.Get("expand") == "true",
-		Separator: st.Get("separator"),
-		Format:    st.Get("format"),
+		Config:       st.Get("config"),
+		Prefix:       st.Get("prefix"),
+		Json:         extractKeyName(st.Get("json")),
+		Yaml:         extractKeyName(st.Get("yaml")),
+		Toml:         extractKeyName(st.Get("toml")),
+		Flag:         extractKeyName(st.Get("flag")),
+		Shorthand:    extractOption(st.Get("flag")),
+		Default:      st.Get("default"),
+		Description:  st.Get("desc"),
+		Required:     st.Get("required") == "true",
+		Min:          st.Get("min"),
+		Max:          st.Get("max"),
+		Len:          st.Get("len"),
+		OneOf:        st.Get("oneof"),
+		Regex:        st.Get("regex"),
+		NonZero:      st.Get("nonzero") == "true",
+		RequiredIf:   st.Get("required_if"),
+		RequiredWith: st.Get("required_with"),
+		ExcludedWith: st.Get("excluded_with"),
+		Secret:       st.Get("secret") == "true",
+		Vault:        st.Get("vault"),
+		Ignore:       st.Get("ignore") == "true",
+		Expand:       st.Get("expand") == "true",
+		Separator:    st.Get("separator"),
+		KVSeparator:  st.Get("kvseparator"),
+		Format:       st.Get("format"),
+		Merge:        st.Get("merge"),
+		tag:          st,
 	}
 
 	if tags.Config == ignoreCharacter {
 		tags.Ignore = true
 	}
+	if tags.Description == "" {
+		tags.Description = st.Get("usage")
+	}
 	if tags.Separator == "" {
 		tags.Separator = defaultSeparator
 	}
+	if tags.KVSeparator == "" {
+		tags.KVSeparator = defaultKVSeparator
+	}
 	if tags.Format == "" {
 		tags.Format = defaultFormat
 	}
@@ -85,3 +184,39 @@ func extractKeyName(key string) string {
 
 	return slice[0]
 }
+
+// It extracts the first option from tag, ignoring key name
+// e.g. calling with "hosts,H" would return "H"
+func extractOption(key string) string {
+	slice := strings.Split(key, ",")
+	if len(slice) < 2 {
+		return ""
+	}
+
+	return slice[1]
+}
+
+// It reports whether tag has option after key name
+// e.g. calling with ",inline" and "inline" would return true
+func hasOption(tag, option string) bool {
+	for _, o := range strings.Split(tag, ",")[1:] {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// profileDefault returns default value of field along with its tag name
+// default.<profile> tags take precedence over default tag, the last active profile having one wins
+func (ct *ConfigTags) profileDefault(profiles []string) (string, string) {
+	for i := len(profiles) - 1; i >= 0; i-- {
+		name := "default." + profiles[i]
+		if value, exists := ct.tag.Lookup(name); exists {
+			return value, name
+		}
+	}
+
+	return ct.Default, "default"
+}
@@ -0,0 +1,111 @@
+package gonfig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage writes a table describing all fields of given struct to w
+// Env var names, flag names and file keys are computed by first provider of each type in Providers list
+// Input argument must be a non-nil struct pointer, it is not modified
+func (c *Config) Usage(w io.Writer, i interface{}) error {
+	if err := validateInput(reflect.ValueOf(i)); err != nil {
+		return err
+	}
+
+	// Resolving fields initializes nil pointers, so a new value of the same type is used
+	in, err := newInput(reflect.New(reflect.TypeOf(i).Elem()).Interface(), c.decoders)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ep  *EnvProvider
+		flp *FlagProvider
+		fip *FileProvider
+	)
+	for _, p := range c.Providers {
+		switch p := p.(type) {
+		case *EnvProvider:
+			if ep == nil {
+				ep = p
+			}
+		case *FlagProvider:
+			if flp == nil {
+				flp = p
+			}
+		case *FileProvider:
+			if fip == nil {
+				fip = p
+			}
+		}
+	}
+	if ep == nil {
+		ep = NewEnvProvider()
+	}
+	if fip == nil {
+		fip = new(FileProvider)
+	}
+
+	if _, err := fmt.Fprintf(w, "Usage of %v:\n", in.Name); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"FIELD", "ENV"}
+	if flp != nil {
+		header = append(header, "FLAG")
+	}
+	header = append(header, "FILE KEY", "TYPE", "DEFAULT", "REQUIRED", "DESCRIPTION")
+	writeTableRow(tw, header)
+
+	for _, f := range in.Fields {
+		env := ep.fieldKey(f)
+		if in.isStructList(f.Value.Type()) {
+			env += ep.FieldSeparator + "*"
+		}
+
+		row := []string{strings.Join(f.Path, "."), env}
+		if flp != nil {
+			row = append(row, flagUsage(in, flp, f))
+		}
+
+		var required string
+		if f.Tags.Required {
+			required = "yes"
+		}
+
+		row = append(
+			row,
+			strings.Join(fip.fieldPath(f), "."),
+			f.Value.Type().String(),
+			fmt.Sprint(redact(f, f.Tags.Default)),
+			required,
+			f.Tags.Description,
+		)
+		writeTableRow(tw, row)
+	}
+
+	return tw.Flush()
+}
+
+// flagUsage returns flag name along with its shorthand
+func flagUsage(in *Input, fp *FlagProvider, f *Field) string {
+	if f.Tags.Flag == ignoreCharacter || in.isStructList(f.Value.Type()) {
+		return ""
+	}
+
+	name := "--" + fp.buildName(f.Tags.Flag, f.Path)
+	if f.Tags.Shorthand != "" {
+		name = "-" + f.Tags.Shorthand + ", " + name
+	}
+
+	return name
+}
+
+func writeTableRow(w io.Writer, cells []string) {
+	_, _ = fmt.Fprintln(w, "  "+strings.Join(cells, "\t"))
+}
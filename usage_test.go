@@ -0,0 +1,90 @@
+package gonfig
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type usageConfig struct {
+	Host    string        `required:"true" desc:"Server host"`
+	Timeout time.Duration `default:"5s" usage:"Request timeout"`
+	Redis   struct {
+		Hosts []string `config:"REDIS" json:"addresses" flag:"redis,r"`
+	}
+}
+
+func TestConfig_Usage(t *testing.T) {
+	var s usageConfig
+
+	t.Run("default providers", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Load().Usage(&b, &s)
+		require.NoError(t, err)
+
+		expected := "" +
+			"Usage of *gonfig.usageConfig:\n" +
+			"  FIELD        ENV      FILE KEY     TYPE           DEFAULT  REQUIRED  DESCRIPTION\n" +
+			"  Host         HOST     Host         string                  yes       Server host\n" +
+			"  Timeout      TIMEOUT  Timeout      time.Duration  5s                 Request timeout\n" +
+			"  Redis.Hosts  REDIS    Redis.Hosts  []string                          \n"
+		assert.Equal(t, expected, b.String())
+	})
+
+	t.Run("configured providers", func(t *testing.T) {
+		ep := NewEnvProvider()
+		ep.Prefix = "APP_"
+
+		var b bytes.Buffer
+		err := Load().
+			AddProvider(ep).
+			AddProvider(NewFlagProvider(nil)).
+			FromFile("config.json").
+			Usage(&b, &s)
+		require.NoError(t, err)
+
+		lines := bytes.Split(b.Bytes(), []byte("\n"))
+		require.Len(t, lines, 6)
+		assert.Equal(t, "  FIELD        ENV          FLAG         FILE KEY         TYPE           DEFAULT  REQUIRED  DESCRIPTION", string(lines[1]))
+		assert.Equal(t, "  Host         APP_HOST     --host       Host             string                  yes       Server host", string(lines[2]))
+		assert.Equal(t, "  Redis.Hosts  APP_REDIS    -r, --redis  Redis.addresses  []string                          ", string(lines[4]))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Load().Usage(&b, s)
+		assert.IsType(t, &InvalidInputError{}, err)
+	})
+
+	t.Run("input is not modified", func(t *testing.T) {
+		var p struct {
+			DB *struct {
+				Host string
+			}
+		}
+
+		var b bytes.Buffer
+		err := Load().Usage(&b, &p)
+		require.NoError(t, err)
+		assert.Contains(t, b.String(), "DB.Host")
+		assert.Nil(t, p.DB)
+	})
+}
+
+func TestConfig_Into_help(t *testing.T) {
+	s := struct {
+		Host string
+	}{}
+
+	err := Load().AddProvider(NewFlagProvider([]string{"--help"})).Into(&s)
+	require.Error(t, err)
+	assert.Truef(
+		t,
+		errors.Is(err, ErrHelp),
+		"Error must wrap ErrHelp error",
+	)
+}
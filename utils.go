@@ -1,13 +1,20 @@
 package gonfig
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
 	firstCapRegex = regexp.MustCompile("([A-Z])([A-Z][a-z])")
 	allCapRegex   = regexp.MustCompile("([a-z0-9])([A-Z])")
 )
@@ -21,10 +28,50 @@ func toSnakeCase(s string) string {
 	return out
 }
 
+// appendPath returns a new path with name appended, leaving the original path untouched
+func appendPath(path []string, name string) []string {
+	newPath := make([]string, len(path), len(path)+1)
+	copy(newPath, path)
+
+	return append(newPath, name)
+}
+
+// appendPaths returns a new slice with names appended, leaving the original slice untouched
+func appendPaths(path []string, names []string) []string {
+	newPath := make([]string, len(path), len(path)+len(names))
+	copy(newPath, path)
+
+	return append(newPath, names...)
+}
+
+// appendPrefix returns a new slice with prefix appended, leaving the original slice untouched
+func appendPrefix(prefixes []structPrefix, prefix structPrefix) []structPrefix {
+	newPrefixes := make([]structPrefix, len(prefixes), len(prefixes)+1)
+	copy(newPrefixes, prefixes)
+
+	return append(newPrefixes, prefix)
+}
+
 func isStruct(t reflect.Type) bool {
 	return t.Kind() == reflect.Struct && !isTime(t) && !isURL(t)
 }
 
+func isBool(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Bool
+}
+
+func isMap(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Map
+}
+
 func isDuration(t reflect.Type) bool {
 	return t.PkgPath() == "time" && t.Name() == "Duration"
 }
@@ -37,39 +84,169 @@ func isURL(t reflect.Type) bool {
 	return t.PkgPath() == "net/url" && t.Name() == "URL"
 }
 
-// traverseMap finds a value in a map based on provided path
-func traverseMap(m map[string]interface{}, path []string) (string, bool) {
-	if len(path) == 0 {
-		return "", false
+// traverseMap finds a value in a map based on provided keys of each level, along with the path it is found at
+// Keys of a level are tried in order, the first one found is used
+func traverseMap(m map[string]interface{}, keys [][]string) (interface{}, []string, bool) {
+	if len(keys) == 0 {
+		return nil, nil, false
 	}
-	first, path := path[0], path[1:]
 
-	value, exists := m[first]
-	if !exists {
-		value, exists = m[strings.ToLower(first)]
-		if !exists {
-			return "", false
+	var value interface{} = m
+	path := make([]string, 0, len(keys))
+	for _, candidates := range keys {
+		if nested, ok := toStringMap(value); ok {
+			k, v, exists := lookupKeys(nested, candidates)
+			if !exists {
+				return nil, nil, false
+			}
+
+			value = v
+			path = append(path, k)
+			continue
+		}
+
+		// Items of lists are accessed by their index
+		list, ok := toList(value)
+		if !ok || len(candidates) == 0 {
+			return nil, nil, false
 		}
+
+		index, err := strconv.Atoi(candidates[0])
+		if err != nil || index < 0 || index >= len(list) {
+			return nil, nil, false
+		}
+
+		value = list[index]
+		path = append(path, candidates[0])
 	}
 
-	if len(path) == 0 {
-		return fmt.Sprint(value), true
+	return value, path, true
+}
+
+// lookupKeys finds the first of keys which exists in map, see lookupFold
+func lookupKeys(m map[string]interface{}, keys []string) (string, interface{}, bool) {
+	for _, key := range keys {
+		if k, v, exists := lookupFold(m, key); exists {
+			return k, v, true
+		}
 	}
 
-	nestedMap, ok := value.(map[string]interface{})
-	if !ok {
-		nestedInterfaceMap, ok := value.(map[interface{}]interface{})
-		if !ok {
-			return "", false
+	return "", nil, false
+}
+
+// lookupFold finds value of key in map along with the matched key, falling back to a case-insensitive match
+// Among keys differing only by case, the first one in sorted order is matched so results are deterministic
+func lookupFold(m map[string]interface{}, key string) (string, interface{}, bool) {
+	if v, exists := m[key]; exists {
+		return key, v, true
+	}
+
+	match, found := "", false
+	for k := range m {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
 		}
+	}
+
+	if !found {
+		return "", nil, false
+	}
 
-		nestedMap = make(map[string]interface{})
-		for k, v := range nestedInterfaceMap {
-			nestedMap[fmt.Sprint(k)] = v
+	return match, m[match], true
+}
+
+// toStringMap converts decoded maps into a map with string keys
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+
+	case map[interface{}]interface{}:
+		sm := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			sm[fmt.Sprint(k)] = v
 		}
+
+		return sm, true
 	}
 
-	return traverseMap(nestedMap, path)
+	return nil, false
+}
+
+// mergeMaps deep merges src into dst, values of src override values of dst except maps which are merged
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := toStringMap(v); ok {
+			if dstMap, ok := toStringMap(dst[k]); ok {
+				mergeMaps(dstMap, srcMap)
+				dst[k] = dstMap
+				continue
+			}
+		}
+
+		dst[k] = v
+	}
+}
+
+// toList converts decoded arrays, or maps keyed by consecutive indexes, into a slice of values
+func toList(value interface{}) ([]interface{}, bool) {
+	if list, ok := value.([]interface{}); ok {
+		return list, true
+	}
+
+	// Items of lists are stored under their index by key value stores, e.g. servers/0/host
+	if m, ok := value.(map[string]interface{}); ok && len(m) != 0 {
+		list := make([]interface{}, len(m))
+		for k, v := range m {
+			index, err := strconv.Atoi(k)
+			if err != nil || index < 0 || index >= len(m) || strconv.Itoa(index) != k {
+				return nil, false
+			}
+
+			list[index] = v
+		}
+
+		return list, true
+	}
+
+	// Arrays of tables are decoded into a slice of maps by toml
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+
+	return list, true
+}
+
+// hasObjects reports whether any item of list is a map
+func hasObjects(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := toStringMap(item); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stringify converts a decoded value into string, joining list items with separator
+func stringify(value interface{}, sep string) string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	items := make([]string, len(list))
+	for i := range list {
+		items[i] = fmt.Sprint(list[i])
+	}
+
+	return strings.Join(items, sep)
 }
 
 // extractItems splits and trims input string based on separator
@@ -84,3 +261,117 @@ func extractItems(str string, sep string) []string {
 
 	return items
 }
+
+// flattenMap collects dot separated paths of all leaf values in a decoded map
+func flattenMap(m map[string]interface{}, prefix string) []string {
+	var keys []string
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := toStringMap(v); ok && len(nested) != 0 {
+			keys = append(keys, flattenMap(nested, key)...)
+			continue
+		}
+
+		// Lists of objects are flattened by index, e.g. servers.0.host
+		if list, ok := toList(v); ok && hasObjects(list) {
+			for i, item := range list {
+				itemKey := key + "." + strconv.Itoa(i)
+				if nested, ok := toStringMap(item); ok && len(nested) != 0 {
+					keys = append(keys, flattenMap(nested, itemKey)...)
+				} else {
+					keys = append(keys, itemKey)
+				}
+			}
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsFold reports whether list contains s, compared case-insensitively
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns keys of map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// closestKey returns the most similar candidate to key, or empty string if none is similar enough
+func closestKey(key string, candidates []string) string {
+	closest, min := "", len(key)/2+1
+	for _, c := range candidates {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(c)); d < min {
+			closest, min = c, d
+		}
+	}
+
+	return closest
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
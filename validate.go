@@ -0,0 +1,343 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validate checks final value of field against its validation tags and returns all failures
+// Values of fields which are not set are checked as well, nil pointers are only checked by nonzero
+func (in *Input) validate(f *Field) []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(
+			validationErrFormat,
+			ErrValidation, in.getPath(f.Path), fmt.Sprintf(format, args...),
+		))
+	}
+
+	if f.Tags.NonZero && f.Value.IsZero() {
+		fail("must not be zero")
+	}
+
+	v := indirect(f.Value)
+	if !v.IsValid() {
+		return errs
+	}
+
+	if f.Tags.Len != "" {
+		if n, err := in.compare(f, v, f.Tags.Len); err != nil {
+			fail("%v", err)
+		} else if n != 0 {
+			fail("length must be %v", f.Tags.Len)
+		}
+	}
+
+	if f.Tags.Min != "" {
+		if n, err := in.compare(f, v, f.Tags.Min); err != nil {
+			fail("%v", err)
+		} else if n < 0 {
+			fail("%v must be at least %v", measure(v), f.Tags.Min)
+		}
+	}
+
+	if f.Tags.Max != "" {
+		if n, err := in.compare(f, v, f.Tags.Max); err != nil {
+			fail("%v", err)
+		} else if n > 0 {
+			fail("%v must be at most %v", measure(v), f.Tags.Max)
+		}
+	}
+
+	if f.Tags.OneOf != "" {
+		options := strings.Fields(f.Tags.OneOf)
+		for _, item := range items(v) {
+			ok, err := in.oneOf(f, item, options)
+			if err != nil {
+				fail("%v", err)
+				break
+			}
+			if !ok {
+				fail("must be one of %v", options)
+				break
+			}
+		}
+	}
+
+	if f.Tags.Regex != "" {
+		re, err := regexp.Compile(f.Tags.Regex)
+		if err != nil {
+			fail("bad regex: %v", err)
+			return errs
+		}
+
+		for _, item := range items(v) {
+			if item.Kind() != reflect.String {
+				fail("regex is not supported for type %v", item.Type())
+				break
+			}
+			if !re.MatchString(item.String()) {
+				fail("must match %q", f.Tags.Regex)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// compare returns -1, 0 or +1 depending on whether value is less than, equal or greater than bound
+// Length of strings, slices, arrays and maps is compared for them
+func (in *Input) compare(f *Field, v reflect.Value, bound string) (int, error) {
+	if hasLength(v) {
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, fmt.Errorf("bad length %q: %w", bound, err)
+		}
+
+		return compareInts(int64(v.Len()), int64(n)), nil
+	}
+
+	b, err := in.parseAs(f, v.Type(), bound)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInts(v.Int(), b.Int()), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case v.Uint() < b.Uint():
+			return -1, nil
+		case v.Uint() > b.Uint():
+			return 1, nil
+		}
+
+		return 0, nil
+
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case v.Float() < b.Float():
+			return -1, nil
+		case v.Float() > b.Float():
+			return 1, nil
+		}
+
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("comparison is not supported for type %v", v.Type())
+}
+
+// oneOf reports whether value equals to any of the options
+func (in *Input) oneOf(f *Field, v reflect.Value, options []string) (bool, error) {
+	for _, option := range options {
+		o, err := in.parseAs(f, v.Type(), option)
+		if err != nil {
+			return false, err
+		}
+
+		if reflect.DeepEqual(v.Interface(), o.Interface()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseAs converts string into a value of given type using field tags
+func (in *Input) parseAs(f *Field, t reflect.Type, value string) (reflect.Value, error) {
+	nf := Field{
+		Value: reflect.New(t).Elem(),
+		Tags:  f.Tags,
+		Path:  f.Path,
+	}
+	if err := in.SetValue(&nf, value); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return nf.Value, nil
+}
+
+// indirect dereferences pointers, returns invalid value for nil pointers
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// items returns dereferenced items of slices and arrays, or value itself for other types
+func items(v reflect.Value) []reflect.Value {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []reflect.Value{v}
+	}
+
+	values := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if item := indirect(v.Index(i)); item.IsValid() {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+func hasLength(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+
+	return false
+}
+
+// measure describes what is compared for value in error messages
+func measure(v reflect.Value) string {
+	if hasLength(v) {
+		return "length"
+	}
+
+	return "value"
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// validateRelations checks field against required_if, required_with and excluded_with tags
+// Referenced paths are relative to the struct containing the field
+func (in *Input) validateRelations(f *Field, fields map[string]*Field) []error {
+	var errs []error
+	resolve := func(ref string) *Field {
+		path := append(append([]string{}, f.Path[:len(f.Path)-1]...), strings.Split(ref, ".")...)
+		sibling, exists := fields[strings.Join(path, ".")]
+		if !exists {
+			errs = append(errs, fmt.Errorf(
+				validationErrFormat,
+				ErrValidation, in.getPath(f.Path), fmt.Sprintf("unknown field %q", ref),
+			))
+		}
+
+		return sibling
+	}
+
+	if f.Tags.RequiredIf != "" && !hasValue(f) {
+		pairs := strings.Fields(f.Tags.RequiredIf)
+		matched := len(pairs)%2 == 0
+		if !matched {
+			errs = append(errs, fmt.Errorf(
+				validationErrFormat,
+				ErrValidation, in.getPath(f.Path), "required_if needs pairs of field and value",
+			))
+		}
+
+		for i := 0; matched && i < len(pairs); i += 2 {
+			sibling := resolve(pairs[i])
+			if sibling == nil {
+				matched = false
+				break
+			}
+
+			v := indirect(sibling.Value)
+			if !v.IsValid() {
+				matched = false
+				break
+			}
+
+			ok, err := in.oneOf(sibling, v, pairs[i+1:i+2])
+			if err != nil {
+				errs = append(errs, err)
+			}
+			matched = ok
+		}
+
+		if matched {
+			errs = append(errs, fmt.Errorf(
+				requiredWhenErrFormat,
+				ErrRequiredField, in.getPath(f.Path), f.Tags.RequiredIf,
+			))
+		}
+	}
+
+	if f.Tags.RequiredWith != "" && !hasValue(f) {
+		for _, ref := range strings.Fields(f.Tags.RequiredWith) {
+			if sibling := resolve(ref); sibling != nil && hasValue(sibling) {
+				errs = append(errs, fmt.Errorf(
+					requiredWhenErrFormat,
+					ErrRequiredField, in.getPath(f.Path), ref+" is set",
+				))
+				break
+			}
+		}
+	}
+
+	if f.Tags.ExcludedWith != "" && hasValue(f) {
+		for _, ref := range strings.Fields(f.Tags.ExcludedWith) {
+			if sibling := resolve(ref); sibling != nil && hasValue(sibling) {
+				errs = append(errs, fmt.Errorf(
+					validationErrFormat,
+					ErrValidation, in.getPath(f.Path), fmt.Sprintf("must not be set when %v is set", ref),
+				))
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// callValidators calls Validate method of nested structs and root struct implementing Validator
+func (in *Input) callValidators() []error {
+	var errs []error
+	for _, s := range in.allStructs() {
+		i := s.Value.Interface()
+		if s.Value.CanAddr() {
+			i = s.Value.Addr().Interface()
+		}
+
+		v, ok := i.(Validator)
+		if !ok {
+			continue
+		}
+
+		if err := v.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf(validatorErrFormat, in.getPath(s.Path), err))
+		}
+	}
+
+	return errs
+}
+
+// fieldsByPath returns fields mapped by their dot separated path
+func (in *Input) fieldsByPath() map[string]*Field {
+	all := in.allFields()
+	fields := make(map[string]*Field, len(all))
+	for _, f := range all {
+		fields[strings.Join(f.Path, ".")] = f
+	}
+
+	return fields
+}
+
+// hasValue reports whether field is set by a provider or has a non-zero value
+func hasValue(f *Field) bool {
+	return f.IsSet || !f.Value.IsZero()
+}
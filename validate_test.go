@@ -0,0 +1,256 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInput_validate(t *testing.T) {
+	input := struct {
+		Port     int               `min:"1" max:"65535"`
+		Ratio    float64           `min:"0" max:"1"`
+		Workers  *uint             `max:"8"`
+		Timeout  string            `regex:"^[0-9]+s$"`
+		Interval time.Duration     `min:"1s" max:"1m"`
+		Name     string            `min:"3" max:"5"`
+		Code     string            `len:"2"`
+		Hosts    []string          `min:"1" max:"2" regex:"^[a-z.]+$"`
+		Labels   map[string]string `max:"1"`
+		Level    string            `oneof:"debug info warn"`
+		Levels   []string          `oneof:"debug info warn"`
+		Weights  []int             `oneof:"1 2 3"`
+		ID       int               `nonzero:"true"`
+		Ptr      *string           `nonzero:"true"`
+	}{}
+
+	tests := []struct {
+		valid   string
+		invalid []string
+	}{
+		{"80", []string{"0", "65536"}},
+		{"0.5", []string{"-0.1", "1.1"}},
+		{"8", []string{"9"}},
+		{"10s", []string{"10m", "s"}},
+		{"30s", []string{"500ms", "2m"}},
+		{"gonf", []string{"go", "gonfig"}},
+		{"ir", []string{"iran"}},
+		{"golang.org go.dev", []string{"a b c", "GOLANG"}},
+		{"a:b", []string{"a:b c:d"}},
+		{"info", []string{"trace"}},
+		{"info warn", []string{"info trace"}},
+		{"1 3", []string{"1 4"}},
+		{"1", []string{"0"}},
+		{"", nil},
+	}
+
+	in, err := NewInput(&input)
+	require.NoError(t, err)
+	require.Equal(t, len(tests), len(in.Fields))
+
+	for i, tc := range tests {
+		f := in.Fields[i]
+		f.IsSet = true
+
+		require.NoError(t, in.SetValue(f, tc.valid))
+		assert.Empty(t, in.validate(f), f.Path)
+
+		for _, value := range tc.invalid {
+			require.NoError(t, in.SetValue(f, value))
+			errs := in.validate(f)
+			require.Len(t, errs, 1, "%v: %v", f.Path, value)
+			assert.Truef(
+				t,
+				errors.Is(errs[0], ErrValidation),
+				"Error must wrap ErrValidation error",
+			)
+		}
+	}
+
+	t.Run("unset fields", func(t *testing.T) {
+		input := struct {
+			Port  int    `min:"1"`
+			Level string `oneof:"debug info"`
+			ID    int    `nonzero:"true"`
+			Ptr   *int   `nonzero:"true"`
+			Max   *int   `min:"1"`
+		}{}
+
+		in, err := NewInput(&input)
+		require.NoError(t, err)
+
+		// Zero values of fields which are not set are validated as well
+		assert.Len(t, in.validate(in.Fields[0]), 1)
+		assert.Len(t, in.validate(in.Fields[1]), 1)
+		assert.Len(t, in.validate(in.Fields[2]), 1)
+		assert.Len(t, in.validate(in.Fields[3]), 1)
+		assert.Empty(t, in.validate(in.Fields[4]))
+	})
+
+	t.Run("final values", func(t *testing.T) {
+		os.Clearenv()
+
+		input := struct {
+			Port  int    `min:"1"`
+			Level string `oneof:"debug info" default:"info"`
+		}{}
+
+		err := Load().FromEnv().Into(&input)
+		require.Error(t, err)
+		ce, ok := err.(ConfigErrors)
+		require.True(t, ok)
+		require.Len(t, ce, 1)
+		assert.Contains(t, ce[0].Error(), ".Port\": value must be at least 1")
+		assert.Equal(t, "info", input.Level)
+
+		err = os.Setenv("PORT", "80")
+		require.NoError(t, err)
+		err = Load().FromEnv().Into(&input)
+		require.NoError(t, err)
+	})
+
+	t.Run("bad tags", func(t *testing.T) {
+		input := struct {
+			Port  int    `min:"one"`
+			Name  string `max:"five"`
+			Level string `regex:"["`
+			Flag  bool   `min:"1"`
+		}{}
+
+		in, err := NewInput(&input)
+		require.NoError(t, err)
+
+		for i, value := range []string{"1", "name", "info", "true"} {
+			f := in.Fields[i]
+			f.IsSet = true
+			require.NoError(t, in.SetValue(f, value))
+			assert.Len(t, in.validate(f), 1, f.Path)
+		}
+	})
+}
+
+func TestConfig_Into_validation(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("PORT", "0")
+	require.NoError(t, err)
+
+	s := struct {
+		Port  int    `min:"1"`
+		Level string `default:"trace" oneof:"debug info"`
+	}{}
+
+	err = Load().FromEnv().Into(&s)
+	require.Error(t, err)
+	ce := err.(ConfigErrors)
+	require.Len(t, ce, 2)
+	assert.Contains(t, ce[0].Error(), ".Port")
+	assert.Contains(t, ce[1].Error(), ".Level")
+	assert.True(t, errors.Is(err, ErrValidation))
+}
+
+var errNoHosts = errors.New("no hosts")
+
+type tlsConfig struct {
+	Enabled  bool
+	Cert     string `required_if:"Enabled true"`
+	Key      string `required_with:"Cert"`
+	Insecure bool   `excluded_with:"Cert"`
+}
+
+type validatedRedis struct {
+	Hosts []string
+}
+
+func (r *validatedRedis) Validate() error {
+	if len(r.Hosts) == 0 {
+		return errNoHosts
+	}
+
+	return nil
+}
+
+type validatedEmbed struct{}
+
+func (e validatedEmbed) Validate() error {
+	return errors.New("embedded")
+}
+
+type validatedConfig struct {
+	validatedEmbed
+	TLS   tlsConfig
+	Redis validatedRedis
+	Port  int
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Port == 0 {
+		return errors.New("port is missing")
+	}
+
+	return nil
+}
+
+func TestInput_validateRelations(t *testing.T) {
+	tests := []struct {
+		tls  tlsConfig
+		errs int
+	}{
+		{tlsConfig{}, 0},
+		{tlsConfig{Enabled: true}, 1},
+		{tlsConfig{Enabled: true, Cert: "cert"}, 1},
+		{tlsConfig{Enabled: true, Cert: "cert", Key: "key"}, 0},
+		{tlsConfig{Cert: "cert", Key: "key", Insecure: true}, 1},
+		{tlsConfig{Insecure: true}, 0},
+	}
+
+	for _, tc := range tests {
+		s := struct {
+			TLS tlsConfig
+		}{tc.tls}
+
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		fields := in.fieldsByPath()
+		var errs []error
+		for _, f := range in.Fields {
+			errs = append(errs, in.validateRelations(f, fields)...)
+		}
+		assert.Len(t, errs, tc.errs, "%+v", tc.tls)
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		s := struct {
+			Cert string `required_with:"Missing"`
+		}{}
+
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		errs := in.validateRelations(in.Fields[0], in.fieldsByPath())
+		require.Len(t, errs, 1)
+		assert.True(t, errors.Is(errs[0], ErrValidation))
+	})
+}
+
+func TestConfig_Into_validators(t *testing.T) {
+	os.Clearenv()
+	err := os.Setenv("TLS_ENABLED", "true")
+	require.NoError(t, err)
+
+	var s validatedConfig
+	err = Load().FromEnv().Into(&s)
+	require.Error(t, err)
+
+	ce := err.(ConfigErrors)
+	require.Len(t, ce, 3)
+	assert.True(t, errors.Is(ce[0], ErrRequiredField))
+	assert.Contains(t, ce[0].Error(), "TLS.Cert")
+	assert.True(t, errors.Is(ce[1], errNoHosts))
+	assert.Contains(t, ce[1].Error(), "gonfig.validatedConfig.Redis: no hosts")
+	assert.EqualError(t, ce[2], "*gonfig.validatedConfig: port is missing")
+}
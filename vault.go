@@ -0,0 +1,422 @@
+package gonfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vaultAddrEnv      = "VAULT_ADDR"
+	vaultTokenEnv     = "VAULT_TOKEN"
+	vaultNamespaceEnv = "VAULT_NAMESPACE"
+	vaultDefaultAddr  = "https://127.0.0.1:8200"
+)
+
+// VaultProvider loads secrets from HashiCorp Vault to provided struct
+// Keys of the secret at Path are mapped onto field paths, and fields with vault tag
+// are set from the referenced key of a secret e.g. `vault:"secret/data/db#password"`
+type VaultProvider struct {
+	// Address of Vault, defaults to $VAULT_ADDR or "https://127.0.0.1:8200"
+	Address string
+
+	// Path of a secret which its keys are mapped onto field paths, e.g. "secret/data/myapp" for KV v2 mounts
+	// Defaults to "", so only fields with vault tag are set
+	Path string
+
+	// KVVersion of secrets engine, either 1 or 2, defaults to 0 which detects it by responses
+	KVVersion int
+
+	// Token is used for authentication, defaults to $VAULT_TOKEN
+	Token string
+
+	// RoleID and SecretID are used for AppRole authentication instead of Token if specified
+	RoleID   string
+	SecretID string
+
+	// AppRoleMount is the path of AppRole auth method, defaults to "approle"
+	AppRoleMount string
+
+	// Namespace of Vault Enterprise, defaults to $VAULT_NAMESPACE
+	Namespace string
+
+	// RefreshInterval is used by Watch to check secrets without a lease for changes, defaults to 0 which disables it
+	// Secrets with a lease are checked when two thirds of their lease duration is passed
+	RefreshInterval time.Duration
+
+	// Whether to report keys of the secret at Path which are not consumed by any field, defaults to false
+	Strict bool
+
+	// Client is used for sending requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	mu sync.Mutex
+
+	// token acquired by AppRole login, renewed when its lease expires
+	authToken  string
+	authExpiry time.Time
+
+	// Secrets read by the last call to Fill, used by Watch to detect changes
+	// Only a hash of secrets is kept, so their values are not held in memory
+	paths []string
+	state [sha256.Size]byte
+	lease time.Duration
+}
+
+var (
+	_ Provider = (*VaultProvider)(nil)
+	_ Filler   = (*VaultProvider)(nil)
+	_ Watcher  = (*VaultProvider)(nil)
+)
+
+// vaultSecret is a secret read from Vault
+type vaultSecret struct {
+	Data          map[string]interface{}
+	LeaseDuration time.Duration
+}
+
+// NewVaultProvider creates a new VaultProvider for secret at path, which can be empty if only vault tags are used
+func NewVaultProvider(path string) *VaultProvider {
+	return &VaultProvider{
+		Path:         path,
+		AppRoleMount: "approle",
+		Strict:       false,
+	}
+}
+
+// Name of provider
+func (vp *VaultProvider) Name() string {
+	return "Vault provider"
+}
+
+// Fill takes struct fields and fills their values from secrets
+// Values referenced by vault tags override values of the secret at Path
+func (vp *VaultProvider) Fill(in *Input) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	paths, err := vp.references(in)
+	if err != nil {
+		return err
+	}
+	if vp.Path != "" {
+		paths = append([]string{vp.Path}, paths...)
+	}
+
+	secrets, err := vp.readAll(ctx, paths)
+	if err != nil {
+		return err
+	}
+
+	if secret, exists := secrets[vp.Path]; vp.Path != "" && exists {
+		fp := FileProvider{FilePath: "vault:" + vp.Path, secret: true}
+		if err := fp.fill(in, in.Fields, secret.Data, vp.Name()); err != nil {
+			return err
+		}
+
+		if vp.Strict || in.strict {
+			if err := fp.unknownKeys(in, secret.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range in.allFields() {
+		if f.Tags.Vault == "" {
+			continue
+		}
+
+		path, key, _ := splitVaultRef(f.Tags.Vault)
+		secret, exists := secrets[path]
+		if !exists {
+			continue
+		}
+
+		value, exists := secret.Data[key]
+		if !exists {
+			continue
+		}
+
+		if err := in.setContent(f, value); err != nil {
+			return err
+		}
+
+		f.markSet(vp.Name(), "vault:"+f.Tags.Vault, secretMask)
+	}
+
+	return nil
+}
+
+// LeaseDuration returns the shortest lease duration of secrets read by the last call to Fill
+// It returns 0 if none of them has a lease, e.g. secrets of KV v2 mounts
+func (vp *VaultProvider) LeaseDuration() time.Duration {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	return vp.lease
+}
+
+// Watch reads secrets of the last call to Fill again and sends on changed if they are modified
+// Secrets are read when two thirds of their lease duration is passed, or every RefreshInterval if they have no lease
+// It blocks until ctx is done if secrets have neither a lease nor RefreshInterval is specified
+func (vp *VaultProvider) Watch(ctx context.Context, changed chan<- struct{}) error {
+	for {
+		vp.mu.Lock()
+		paths, last, wait := vp.paths, vp.state, vp.lease*2/3
+		vp.mu.Unlock()
+
+		if wait == 0 {
+			wait = vp.RefreshInterval
+		}
+		if wait == 0 {
+			<-ctx.Done()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		readCtx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		_, err := vp.readAll(readCtx, paths)
+		cancel()
+
+		vp.mu.Lock()
+		current := vp.state
+		vp.mu.Unlock()
+
+		// Failed reads are retried after the same duration, reloading would fail anyway
+		if err == nil && current != last {
+			notify(changed)
+		}
+	}
+}
+
+// references returns paths of secrets referenced by vault tags, without duplicates
+func (vp *VaultProvider) references(in *Input) ([]string, error) {
+	var paths []string
+	for _, f := range in.allFields() {
+		if f.Tags.Vault == "" {
+			continue
+		}
+
+		path, _, ok := splitVaultRef(f.Tags.Vault)
+		if !ok {
+			return nil, fmt.Errorf(badVaultRefErrFormat, f.Tags.Vault, in.getPath(f.Path))
+		}
+
+		if !contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// readAll reads secrets at paths, secrets which are not found are skipped
+// Read secrets are recorded along with their shortest lease to be watched for changes
+func (vp *VaultProvider) readAll(ctx context.Context, paths []string) (map[string]*vaultSecret, error) {
+	secrets := make(map[string]*vaultSecret, len(paths))
+	var lease time.Duration
+	for _, path := range paths {
+		if _, exists := secrets[path]; exists {
+			continue
+		}
+
+		secret, err := vp.read(ctx, path)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		secrets[path] = secret
+		if secret.LeaseDuration > 0 && (lease == 0 || secret.LeaseDuration < lease) {
+			lease = secret.LeaseDuration
+		}
+	}
+
+	// Secrets are encoded as JSON, which sorts map keys, so the same secrets result in the same state
+	state, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	vp.mu.Lock()
+	vp.paths, vp.state, vp.lease = paths, sha256.Sum256(state), lease
+	vp.mu.Unlock()
+
+	return secrets, nil
+}
+
+// read returns the secret at path, data of KV v2 secrets is unwrapped
+func (vp *VaultProvider) read(ctx context.Context, path string) (*vaultSecret, error) {
+	token, err := vp.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		LeaseDuration int64                  `json:"lease_duration"`
+		Data          map[string]interface{} `json:"data"`
+	}
+	if err := vp.send(ctx, http.MethodGet, path, token, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	data := resp.Data
+	if vp.isKVv2(data) {
+		data, _ = data["data"].(map[string]interface{})
+	}
+
+	return &vaultSecret{
+		Data:          data,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+	}, nil
+}
+
+// isKVv2 reports whether data is a secret of KV v2 mount, which wraps data along with its metadata
+func (vp *VaultProvider) isKVv2(data map[string]interface{}) bool {
+	switch vp.KVVersion {
+	case 1:
+		return false
+	case 2:
+		return true
+	}
+
+	_, hasData := data["data"]
+	_, hasMetadata := data["metadata"].(map[string]interface{})
+	return len(data) == 2 && hasData && hasMetadata
+}
+
+// token returns Token, or a token acquired by AppRole login if RoleID is specified
+// AppRole tokens are reused until their lease is about to expire
+func (vp *VaultProvider) token(ctx context.Context) (string, error) {
+	if vp.RoleID == "" {
+		if vp.Token != "" {
+			return vp.Token, nil
+		}
+
+		return os.Getenv(vaultTokenEnv), nil
+	}
+
+	vp.mu.Lock()
+	token, expiry := vp.authToken, vp.authExpiry
+	vp.mu.Unlock()
+
+	if token != "" && (expiry.IsZero() || time.Now().Before(expiry)) {
+		return token, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role_id": vp.RoleID, "secret_id": vp.SecretID}
+	if err := vp.send(ctx, http.MethodPost, "auth/"+vp.appRoleMount()+"/login", "", body, &resp); err != nil {
+		return "", err
+	}
+
+	token, expiry = resp.Auth.ClientToken, time.Time{}
+	if ttl := time.Duration(resp.Auth.LeaseDuration) * time.Second; ttl > 0 {
+		// Tokens are renewed a bit earlier than expiry, so they do not expire during requests
+		expiry = time.Now().Add(ttl * 9 / 10)
+	}
+
+	vp.mu.Lock()
+	vp.authToken, vp.authExpiry = token, expiry
+	vp.mu.Unlock()
+
+	return token, nil
+}
+
+// send sends a request to path of Vault API and decodes the response into v
+func (vp *VaultProvider) send(ctx context.Context, method, path, token string, body, v interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	u := vp.address() + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if ns := vp.namespace(); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, _, err := fetch(vp.Client, req)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(resp))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf(decodeFailedErrFormat, err)
+	}
+
+	return nil
+}
+
+// address returns Address, falling back to $VAULT_ADDR and the default address
+func (vp *VaultProvider) address() string {
+	addr := vp.Address
+	if addr == "" {
+		addr = os.Getenv(vaultAddrEnv)
+	}
+	if addr == "" {
+		addr = vaultDefaultAddr
+	}
+
+	return strings.TrimRight(addr, "/")
+}
+
+// namespace returns Namespace, falling back to $VAULT_NAMESPACE
+func (vp *VaultProvider) namespace() string {
+	if vp.Namespace != "" {
+		return vp.Namespace
+	}
+
+	return os.Getenv(vaultNamespaceEnv)
+}
+
+// appRoleMount returns AppRoleMount, or the default path of AppRole auth method
+func (vp *VaultProvider) appRoleMount() string {
+	if vp.AppRoleMount != "" {
+		return strings.Trim(vp.AppRoleMount, "/")
+	}
+
+	return "approle"
+}
+
+// splitVaultRef splits a vault tag into path of secret and key, e.g. "secret/data/db#password"
+func splitVaultRef(ref string) (path string, key string, ok bool) {
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", false
+	}
+
+	return ref[:i], ref[i+1:], true
+}
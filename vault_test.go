@@ -0,0 +1,334 @@
+package gonfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault emulates KV v1 and v2 secrets engines and AppRole login of Vault HTTP API
+// Secrets under secret/ are served as KV v2, others as KV v1 with a lease
+type fakeVault struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]interface{}
+	tokens  map[string]bool
+	logins  int
+	ttl     int
+}
+
+func newFakeVault(secrets map[string]map[string]interface{}) *fakeVault {
+	return &fakeVault{
+		secrets: secrets,
+		tokens:  map[string]bool{"root": true},
+		ttl:     3600,
+	}
+}
+
+func (fv *fakeVault) set(path, key string, value interface{}) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	fv.secrets[path][key] = value
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if path == "auth/approle/login" {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "s3cr3t" {
+			http.Error(w, `{"errors":["invalid role or secret ID"]}`, http.StatusBadRequest)
+			return
+		}
+
+		fv.logins++
+		fv.tokens["approle"] = true
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "approle", "lease_duration": fv.ttl},
+		})
+		return
+	}
+
+	if !fv.tokens[r.Header.Get("X-Vault-Token")] {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+
+	if strings.HasPrefix(path, "secret/data/") {
+		data, exists := fv.secrets[strings.Replace(path, "/data/", "/", 1)]
+		if !exists {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_duration": 0,
+			"data":           map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": 1}},
+		})
+		return
+	}
+
+	data, exists := fv.secrets[path]
+	if !exists {
+		http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"lease_duration": fv.ttl,
+		"data":           data,
+	})
+}
+
+type vaultConfig struct {
+	Host string
+	DB   struct {
+		User     string
+		Password string `vault:"database/creds#password"`
+	}
+	APIKey string `vault:"secret/data/api#key"`
+	Port   int
+}
+
+func TestNewVaultProvider(t *testing.T) {
+	vp := NewVaultProvider("secret/data/app")
+	require.NotNil(t, vp)
+	assert.Equal(t, "", vp.Address)
+	assert.Equal(t, "secret/data/app", vp.Path)
+	assert.Equal(t, 0, vp.KVVersion)
+	assert.Equal(t, "", vp.Token)
+	assert.Equal(t, "approle", vp.AppRoleMount)
+	assert.Equal(t, time.Duration(0), vp.RefreshInterval)
+	assert.Equal(t, false, vp.Strict)
+}
+
+func TestVaultProvider_Name(t *testing.T) {
+	vp := NewVaultProvider("")
+	assert.Equal(t, "Vault provider", vp.Name())
+}
+
+func TestVaultProvider_Fill(t *testing.T) {
+	fv := newFakeVault(map[string]map[string]interface{}{
+		"secret/app":     {"host": "golang.org", "port": 8080, "db": map[string]interface{}{"user": "gopher"}},
+		"secret/api":     {"key": "k3y"},
+		"database/creds": {"password": "p4ss"},
+		"kv/app":         {"host": "v1.golang.org", "unknown": "x"},
+		"secret/bad":     {"port": "s3cr3t-value"},
+	})
+	server := httptest.NewServer(fv)
+	defer server.Close()
+
+	newProvider := func(path string) *VaultProvider {
+		vp := NewVaultProvider(path)
+		vp.Address = server.URL
+		vp.Token = "root"
+		return vp
+	}
+
+	t.Run("kv v2 and references", func(t *testing.T) {
+		var s vaultConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		vp := newProvider("secret/data/app")
+		err = vp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "golang.org", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "gopher", s.DB.User)
+		assert.Equal(t, "p4ss", s.DB.Password)
+		assert.Equal(t, "k3y", s.APIKey)
+
+		assert.Equal(t, "vault:secret/data/app:Host", in.Fields[0].Key)
+		assert.Equal(t, "vault:database/creds#password", in.Fields[2].Key)
+		assert.Equal(t, time.Hour, vp.LeaseDuration())
+
+		// Values of secrets are always redacted in provenance
+		for _, f := range in.Fields {
+			if f.IsSet {
+				assert.Equal(t, secretMask, f.RawValue, f.Path)
+			}
+		}
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		var s vaultConfig
+		c := Load().AddProvider(newProvider("secret/data/app"))
+		err := c.Into(&s)
+		require.NoError(t, err)
+
+		report := c.Explain()
+		assert.Contains(t, report, "vault:secret/data/app:Host")
+		for _, value := range []string{"golang.org", "gopher", "p4ss", "k3y", "8080"} {
+			assert.NotContains(t, report, value)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		in, err := NewInput(&vaultConfig{})
+		require.NoError(t, err)
+
+		err = newProvider("secret/data/bad").Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrParsing))
+		assert.NotContains(t, err.Error(), "s3cr3t-value")
+	})
+
+	t.Run("kv v1", func(t *testing.T) {
+		var s vaultConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		vp := newProvider("kv/app")
+		vp.KVVersion = 1
+		err = vp.Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "v1.golang.org", s.Host)
+
+		vp.Strict = true
+		err = vp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUnknownKey))
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+		err := os.Setenv(vaultAddrEnv, server.URL)
+		require.NoError(t, err)
+		err = os.Setenv(vaultTokenEnv, "root")
+		require.NoError(t, err)
+
+		var s vaultConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		err = NewVaultProvider("").Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "", s.Host)
+		assert.Equal(t, "k3y", s.APIKey)
+	})
+
+	t.Run("approle", func(t *testing.T) {
+		var s vaultConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		vp := newProvider("")
+		vp.Token = ""
+		vp.RoleID = "role"
+		vp.SecretID = "s3cr3t"
+		for i := 0; i < 2; i++ {
+			err = vp.Fill(in)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "p4ss", s.DB.Password)
+		assert.Equal(t, 1, fv.logins)
+
+		vp.SecretID = "wrong"
+		vp.authToken = ""
+		err = vp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequestFailed))
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		in, err := NewInput(&vaultConfig{})
+		require.NoError(t, err)
+
+		vp := newProvider("secret/data/app")
+		vp.Token = "invalid"
+		err = vp.Fill(in)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrRequestFailed))
+		assert.NotContains(t, err.Error(), "invalid")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var s vaultConfig
+		in, err := NewInput(&s)
+		require.NoError(t, err)
+
+		err = newProvider("secret/data/missing").Fill(in)
+		require.NoError(t, err)
+		assert.Equal(t, "", s.Host)
+	})
+
+	t.Run("bad reference", func(t *testing.T) {
+		in, err := NewInput(&struct {
+			Password string `vault:"database/creds"`
+		}{})
+		require.NoError(t, err)
+
+		err = newProvider("").Fill(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `bad vault reference "database/creds"`)
+	})
+}
+
+func TestVaultProvider_Watch(t *testing.T) {
+	fv := newFakeVault(map[string]map[string]interface{}{
+		"secret/app": {"host": "a.com"},
+	})
+	server := httptest.NewServer(fv)
+	defer server.Close()
+
+	vp := NewVaultProvider("secret/data/app")
+	vp.Address = server.URL
+	vp.Token = "root"
+	vp.RefreshInterval = 10 * time.Millisecond
+
+	var s vaultConfig
+	in, err := NewInput(&s)
+	require.NoError(t, err)
+	require.NoError(t, vp.Fill(in))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error)
+	go func() {
+		done <- vp.Watch(ctx, changed)
+	}()
+
+	select {
+	case <-changed:
+		t.Fatal("unchanged secret was reported")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fv.set("secret/app", "host", "b.com")
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change was not detected")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestSplitVaultRef(t *testing.T) {
+	path, key, ok := splitVaultRef("secret/data/db#password")
+	assert.True(t, ok)
+	assert.Equal(t, "secret/data/db", path)
+	assert.Equal(t, "password", key)
+
+	for _, ref := range []string{"secret/data/db", "#password", "secret/data/db#"} {
+		_, _, ok := splitVaultRef(ref)
+		assert.False(t, ok, ref)
+	}
+}
@@ -0,0 +1,197 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// pollInterval is used to check files for changes when inotify is not available
+	pollInterval = time.Second
+
+	// watchDebounce is used to wait for more changes before reloading, e.g. editors writing files in multiple steps
+	watchDebounce = 100 * time.Millisecond
+)
+
+// Watch keeps watching sources of providers implementing Watcher and reloads values on changes
+// On every change all providers are applied into a fresh copy of the struct
+// and i is only replaced when loading succeeds, so last good values are kept on errors
+// onChange is called with nil after i is replaced, or with the error of a failed reload
+// i is replaced from another goroutine, so it must be read between RLock and RUnlock calls while watching
+// It blocks until ctx is done or a watcher fails
+func (c *Config) Watch(ctx context.Context, i interface{}, onChange func(err error)) error {
+	v := reflect.ValueOf(i)
+	if err := validateInput(v); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	providers := c.providers(c.Profiles())
+	changed := make(chan struct{}, 1)
+	errs := make(chan error, len(providers))
+
+	for _, p := range providers {
+		w, ok := p.(Watcher)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(p Provider, w Watcher) {
+			defer wg.Done()
+
+			if err := w.Watch(ctx, changed); err != nil && ctx.Err() == nil {
+				errs <- fmt.Errorf("%v: %w", p.Name(), err)
+			}
+		}(p, w)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-errs:
+			return err
+
+		case <-changed:
+			if !debounce(ctx, changed) {
+				return ctx.Err()
+			}
+
+			err := c.reload(v)
+			if onChange != nil {
+				onChange(err)
+			}
+		}
+	}
+}
+
+// reload loads values into a fresh copy of struct and replaces the pointed value on success
+// The value is replaced while holding the lock, along with Input used by Explain
+func (c *Config) reload(v reflect.Value) error {
+	fresh := reflect.New(v.Type().Elem())
+	in, ce, err := c.load(fresh.Interface())
+	if err != nil {
+		return err
+	}
+	if len(ce) != 0 {
+		return ce
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v.Elem().Set(fresh.Elem())
+	c.in = in
+	return nil
+}
+
+// debounce waits for changes to settle, returns false if ctx is done meanwhile
+func debounce(ctx context.Context, changed <-chan struct{}) bool {
+	timer := time.NewTimer(watchDebounce)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+
+		case <-changed:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(watchDebounce)
+
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// notify sends on changed without blocking, a pending notification is enough to trigger a reload
+func notify(changed chan<- struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// pollFile checks file for changes in modification time, size and existence every pollInterval
+func pollFile(ctx context.Context, path string, changed chan<- struct{}) error {
+	return poll(ctx, func() interface{} { return fileState(path) }, changed)
+}
+
+// pollDirectory checks files inside dir for changes in modification time, size and existence every pollInterval
+func pollDirectory(ctx context.Context, dir string, changed chan<- struct{}) error {
+	return poll(ctx, func() interface{} { return directoryState(dir) }, changed)
+}
+
+// poll sends on changed whenever the comparable value returned by state changes
+func poll(ctx context.Context, state func() interface{}, changed chan<- struct{}) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	last := state()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			current := state()
+			if current != last {
+				last = current
+				notify(changed)
+			}
+		}
+	}
+}
+
+type fileStat struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func fileState(path string) fileStat {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+
+	return fileStat{
+		exists:  true,
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+	}
+}
+
+// directoryState returns state of files inside dir as a string, symlinks are followed
+func directoryState(dir string) string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, e := range entries {
+		st := fileState(filepath.Join(dir, e.Name()))
+		_, _ = fmt.Fprintf(&b, "%v:%v:%v:%v\n", e.Name(), st.exists, st.size, st.modTime.UnixNano())
+	}
+
+	return b.String()
+}
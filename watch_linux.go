@@ -0,0 +1,106 @@
+//go:build linux
+// +build linux
+
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	inotifyMask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM
+
+	// kubernetesDataDir is replaced atomically by Kubernetes when mounted ConfigMaps and Secrets are updated
+	kubernetesDataDir = "..data"
+)
+
+// watchFile watches parent directory of file using inotify, so replaced and recreated files are detected
+// It falls back to polling if inotify is not available
+func watchFile(ctx context.Context, path string, changed chan<- struct{}) error {
+	dir, name := filepath.Split(filepath.Clean(path))
+	if dir == "" {
+		dir = "."
+	}
+
+	match := func(eventName string) bool {
+		return eventName == name || eventName == kubernetesDataDir
+	}
+
+	err := inotifyWatch(ctx, dir, match, changed)
+	if err == errInotifyUnavailable {
+		return pollFile(ctx, path, changed)
+	}
+
+	return err
+}
+
+// watchDirectory watches any change of files inside dir using inotify
+// It falls back to polling if inotify is not available
+func watchDirectory(ctx context.Context, dir string, changed chan<- struct{}) error {
+	match := func(string) bool {
+		return true
+	}
+
+	err := inotifyWatch(ctx, dir, match, changed)
+	if err == errInotifyUnavailable {
+		return pollDirectory(ctx, dir, changed)
+	}
+
+	return err
+}
+
+// errInotifyUnavailable is returned by inotifyWatch if inotify can not be used for dir
+var errInotifyUnavailable = errors.New("inotify unavailable")
+
+// inotifyWatch sends on changed for events of dir entries which their name matches, until ctx is done
+func inotifyWatch(ctx context.Context, dir string, match func(eventName string) bool, changed chan<- struct{}) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return errInotifyUnavailable
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		_ = syscall.Close(fd)
+		return errInotifyUnavailable
+	}
+
+	// Non-blocking descriptor is registered in runtime poller, so closing it unblocks Read
+	f := os.NewFile(uintptr(fd), "inotify")
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = f.Close()
+	}()
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(event.Len)
+
+			if match(strings.TrimRight(string(buf[start:offset]), "\x00")) {
+				notify(changed)
+			}
+		}
+	}
+}
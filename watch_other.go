@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package gonfig
+
+import "context"
+
+// watchFile polls file for changes, inotify is only used on linux
+func watchFile(ctx context.Context, path string, changed chan<- struct{}) error {
+	return pollFile(ctx, path, changed)
+}
+
+// watchDirectory polls files inside dir for changes, inotify is only used on linux
+func watchDirectory(ctx context.Context, dir string, changed chan<- struct{}) error {
+	return pollDirectory(ctx, dir, changed)
+}
@@ -0,0 +1,148 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Watch(t *testing.T) {
+	setDuration(t, &watchDebounce, 10*time.Millisecond)
+	os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := ioutil.WriteFile(path, []byte(`{"host": "golang.org"}`), 0600)
+	require.NoError(t, err)
+
+	s := struct {
+		Host string `required:"true"`
+		Port int    `default:"80"`
+	}{}
+	c := Load().FromFile(path)
+	require.NoError(t, c.Into(&s))
+	require.Equal(t, "golang.org", s.Host)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// Writes may cause extra reloads, so they are buffered not to block the watcher
+	reloads := make(chan error, 16)
+	watchErr := make(chan error)
+	go func() {
+		watchErr <- c.Watch(ctx, &s, func(err error) {
+			reloads <- err
+		})
+	}()
+
+	// Readers hold the lock while values are being replaced
+	readCtx, stopReading := context.WithCancel(context.Background())
+	defer stopReading()
+	go func() {
+		for readCtx.Err() == nil {
+			c.RLock()
+			_ = s.Host + strconv.Itoa(s.Port)
+			c.RUnlock()
+			_ = c.Explain()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Give the watcher a moment to start watching
+	time.Sleep(50 * time.Millisecond)
+
+	err = ioutil.WriteFile(path, []byte(`{"host": "go.dev", "port": 8080}`), 0600)
+	require.NoError(t, err)
+	require.NoError(t, waitReload(t, reloads, func(err error) bool { return err == nil }))
+	assert.Equal(t, "go.dev", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.Contains(t, c.Explain(), "go.dev")
+
+	err = ioutil.WriteFile(path, []byte(`{"port": 443}`), 0600)
+	require.NoError(t, err)
+	err = waitReload(t, reloads, func(err error) bool { return err != nil })
+	require.Error(t, err)
+	assert.Truef(
+		t,
+		errors.Is(err, ErrRequiredField),
+		"Error must wrap ErrRequiredField error",
+	)
+	assert.Equal(t, "go.dev", s.Host)
+	assert.Equal(t, 8080, s.Port)
+
+	// Failed reloads keep explaining the last good values
+	assert.Contains(t, c.Explain(), "go.dev")
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-watchErr)
+}
+
+func TestConfig_Watch_invalidInput(t *testing.T) {
+	err := Load().Watch(context.Background(), struct{}{}, nil)
+	assert.IsType(t, &InvalidInputError{}, err)
+}
+
+func TestPollFile(t *testing.T) {
+	setDuration(t, &pollInterval, 10*time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), ".env")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	done := make(chan error)
+	go func() {
+		done <- pollFile(ctx, path, changed)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	err := ioutil.WriteFile(path, []byte("HOST=golang.org"), 0600)
+	require.NoError(t, err)
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("change was not detected")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestEnvProvider_Watch(t *testing.T) {
+	ep := NewEnvProvider()
+	err := ep.Watch(context.Background(), make(chan struct{}))
+	assert.NoError(t, err)
+}
+
+// waitReload returns the error of the first reload which done reports true for, skipping reloads of earlier changes
+func waitReload(t *testing.T, reloads <-chan error, done func(err error) bool) error {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-reloads:
+			if done(err) {
+				return err
+			}
+		case <-timeout:
+			t.Fatal("config was not reloaded")
+			return nil
+		}
+	}
+}
+
+// setDuration sets d to value and restores it at the end of the test
+func setDuration(t *testing.T, d *time.Duration, value time.Duration) {
+	old := *d
+	*d = value
+	t.Cleanup(func() {
+		*d = old
+	})
+}